@@ -0,0 +1,73 @@
+package input
+
+import "errors"
+
+const PayloadText = "text"
+
+var ErrNotText = errors.New("the message is not a text")
+
+// Payload is a message received from a chat channel, like the webhook of a
+// messaging platform. Type is PayloadText when it is empty.
+type Payload struct {
+	Channel  string `json:"channel"`
+	User     string `json:"user"`
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Context  string `json:"context"`
+	Language string `json:"lang"`
+}
+
+// channelInput reads the payloads of chat channels and keeps a session per
+// user of a channel, it also gives a session to the statements that have a
+// user but no session.
+type channelInput struct{}
+
+func NewChannelInput() InputAdapter {
+	return channelInput{}
+}
+
+func (channelInput) Process(request interface{}) (Statement, error) {
+	var statement Statement
+	switch value := request.(type) {
+	case Payload:
+		return payloadStatement(value)
+	case *Payload:
+		return payloadStatement(*value)
+	case Statement:
+		statement = value
+	case *Statement:
+		statement = *value
+	default:
+		return Statement{}, ErrUnsupported
+	}
+
+	if statement.Session == "" && statement.User != "" {
+		statement.Session = channelSession(statement.Channel, statement.User)
+	}
+	return statement, nil
+}
+
+func payloadStatement(payload Payload) (Statement, error) {
+	if payload.Type != "" && payload.Type != PayloadText {
+		return Statement{}, ErrNotText
+	}
+
+	statement := Statement{
+		Text:     payload.Text,
+		Context:  payload.Context,
+		Language: payload.Language,
+		Channel:  payload.Channel,
+		User:     payload.User,
+	}
+	if payload.User != "" {
+		statement.Session = channelSession(payload.Channel, payload.User)
+	}
+	return statement, nil
+}
+
+func channelSession(channel, user string) string {
+	if channel == "" {
+		return user
+	}
+	return channel + ":" + user
+}
@@ -1,5 +1,51 @@
 package input
 
-type InputAdapter interface {
-	Process(interface{})
+import "errors"
+
+var (
+	// ErrUnsupported is returned by the adapters for the requests they do not
+	// read, the pipeline passes them on to the next adapter.
+	ErrUnsupported = errors.New("unsupported input")
+	ErrEmptyText   = errors.New("empty text")
+)
+
+type (
+	// Statement is a request normalized by the input adapters.
+	Statement struct {
+		Text     string `json:"text"`
+		Context  string `json:"context,omitempty"`
+		Session  string `json:"session,omitempty"`
+		Language string `json:"lang,omitempty"`
+		Channel  string `json:"channel,omitempty"`
+		User     string `json:"user,omitempty"`
+	}
+
+	// InputAdapter reads a raw request, or the statement given by the
+	// previous adapter of a pipeline.
+	InputAdapter interface {
+		Process(interface{}) (Statement, error)
+	}
+)
+
+// Pipeline runs the adapters in turn, each one normalizing what the previous
+// one gave. The adapters not supporting a request are skipped, the request
+// is unsupported when none of them reads it.
+func Pipeline(request interface{}, adapters ...InputAdapter) (Statement, error) {
+	current := request
+	for _, adapter := range adapters {
+		statement, err := adapter.Process(current)
+		if err == ErrUnsupported {
+			continue
+		}
+		if err != nil {
+			return Statement{}, err
+		}
+		current = statement
+	}
+
+	statement, ok := current.(Statement)
+	if !ok {
+		return Statement{}, ErrUnsupported
+	}
+	return statement, nil
 }
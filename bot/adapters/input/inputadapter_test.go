@@ -0,0 +1,61 @@
+package input
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	adapters := []InputAdapter{NewJSONInput(), NewChannelInput(), NewTextInput()}
+	for _, test := range []struct {
+		request   interface{}
+		statement Statement
+	}{
+		{
+			request:   "  what is\tthe  time? ",
+			statement: Statement{Text: "what is the time?"},
+		},
+		{
+			request:   []byte(`{"question": " hello ", "session": "42", "lang": "en"}`),
+			statement: Statement{Text: "hello", Session: "42", Language: "en"},
+		},
+		{
+			request: strings.NewReader(`{"text": "hi", "channel": "slack", "user": "alice"}`),
+			statement: Statement{Text: "hi", Session: "slack:alice", Channel: "slack",
+				User: "alice"},
+		},
+		{
+			request: Payload{Channel: "wechat", User: "bob", Text: "你好 ", Context: "greeting"},
+			statement: Statement{Text: "你好", Context: "greeting", Session: "wechat:bob",
+				Channel: "wechat", User: "bob"},
+		},
+	} {
+		statement, err := Pipeline(test.request, adapters...)
+		if err != nil {
+			t.Errorf("could not read %v: %s", test.request, err.Error())
+			continue
+		}
+		if statement != test.statement {
+			t.Errorf("expected %+v, got %+v", test.statement, statement)
+		}
+	}
+}
+
+func TestPipeline_Errors(t *testing.T) {
+	adapters := []InputAdapter{NewJSONInput(), NewChannelInput(), NewTextInput()}
+	for request, expected := range map[interface{}]error{
+		42:                                ErrUnsupported,
+		"   ":                             ErrEmptyText,
+		Payload{Type: "image"}:            ErrNotText,
+		`{"session": "42"}`:               ErrEmptyText,
+		Payload{Text: "hi", Type: "text"}: nil,
+	} {
+		if _, err := Pipeline(request, adapters...); err != expected {
+			t.Errorf("expected %v for %v, got %v", expected, request, err)
+		}
+	}
+
+	if _, err := Pipeline(`{"text": `, adapters...); err == nil {
+		t.Error("expected invalid JSON to fail")
+	}
+}
@@ -0,0 +1,61 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// jsonMessage is a JSON request, the text can be given as "question" too.
+type jsonMessage struct {
+	Statement
+	Question string `json:"question"`
+}
+
+// jsonInput reads JSON messages like {"text": "hello", "session": "42"}.
+type jsonInput struct{}
+
+func NewJSONInput() InputAdapter {
+	return jsonInput{}
+}
+
+func (jsonInput) Process(request interface{}) (Statement, error) {
+	var data []byte
+	switch value := request.(type) {
+	case []byte:
+		data = value
+	case json.RawMessage:
+		data = value
+	case string:
+		data = []byte(value)
+	case io.Reader:
+		content, err := ioutil.ReadAll(value)
+		if err != nil {
+			return Statement{}, err
+		}
+		data = content
+	case map[string]interface{}:
+		content, err := json.Marshal(value)
+		if err != nil {
+			return Statement{}, err
+		}
+		data = content
+	default:
+		return Statement{}, ErrUnsupported
+	}
+
+	// plain text is left to the other adapters
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return Statement{}, ErrUnsupported
+	}
+
+	var message jsonMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return Statement{}, err
+	}
+	if message.Text == "" {
+		message.Text = message.Question
+	}
+	return message.Statement, nil
+}
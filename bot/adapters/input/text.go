@@ -0,0 +1,58 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// textInput reads plain text, it trims the text, drops control characters and
+// collapses white spaces.
+type textInput struct{}
+
+func NewTextInput() InputAdapter {
+	return textInput{}
+}
+
+func (textInput) Process(request interface{}) (Statement, error) {
+	var statement Statement
+	switch value := request.(type) {
+	case string:
+		statement.Text = value
+	case []byte:
+		statement.Text = string(value)
+	case Statement:
+		statement = value
+	case *Statement:
+		statement = *value
+	case fmt.Stringer:
+		statement.Text = value.String()
+	default:
+		return Statement{}, ErrUnsupported
+	}
+
+	statement.Text = normalizeText(statement.Text)
+	if statement.Text == "" {
+		return Statement{}, ErrEmptyText
+	}
+	return statement, nil
+}
+
+func normalizeText(text string) string {
+	var builder strings.Builder
+	space := false
+	for _, r := range strings.TrimSpace(text) {
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+		case unicode.IsControl(r):
+		default:
+			if space && builder.Len() > 0 {
+				builder.WriteByte(' ')
+			}
+			space = false
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,196 @@
+package logic
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+const (
+	DefaultBM25K1 = 1.2
+	DefaultBM25B  = 0.75
+)
+
+type (
+	// bm25Match ranks the candidates of Search with BM25, using the tokens and
+	// the idf of the storage.
+	bm25Match struct {
+		verbose  bool
+		storage  storage.StorageAdapter
+		analyzer storage.Analyzer
+		tops     int
+		k1       float64
+		b        float64
+	}
+
+	// fieldsAnalyzer is used for storages that are not analyzers, it splits
+	// on white spaces and weights every term the same.
+	fieldsAnalyzer struct{}
+)
+
+// NewBM25Match creates a BM25 adapter, k1 and b fall back to DefaultBM25K1
+// and DefaultBM25B when they are not positive.
+func NewBM25Match(store storage.StorageAdapter, tops int, k1, b float64) LogicAdapter {
+	if k1 <= 0 {
+		k1 = DefaultBM25K1
+	}
+	if b <= 0 {
+		b = DefaultBM25B
+	}
+
+	analyzer, ok := store.(storage.Analyzer)
+	if !ok {
+		analyzer = fieldsAnalyzer{}
+	}
+
+	return &bm25Match{
+		storage:  store,
+		analyzer: analyzer,
+		tops:     tops,
+		k1:       k1,
+		b:        b,
+	}
+}
+
+func (match *bm25Match) CanProcess(string) bool {
+	return true
+}
+
+func (match *bm25Match) Process(text string, context ...string) []Answer {
+	return match.ProcessClass(text, "", context...)
+}
+
+// ProcessClass scores the questions of the class only, of any class when it
+// is empty.
+func (match *bm25Match) ProcessClass(text, class string, context ...string) []Answer {
+	if responses, ok := find(match.storage, text, class, context...); ok {
+		return topAnswers(responses, match.tops)
+	}
+
+	candidates, expansions := search(match.storage, text)
+	candidates = restrict(match.storage, candidates, class, context...)
+	if match.verbose {
+		printMatches(candidates)
+	}
+
+	scores := match.score(text, candidates, expansions)
+	var answers []Answer
+	for _, each := range scores {
+		if len(answers) >= match.tops {
+			break
+		}
+		if each.score <= 0 {
+			break
+		}
+		if responses, ok := find(match.storage, each.question, class, context...); ok {
+			if matches := topAnswers(responses, 1); len(matches) > 0 {
+				matches[0].Confidence = each.score
+				matches[0].Expansions = each.expansions
+				answers = append(answers, matches[0])
+			}
+		}
+	}
+
+	return answers
+}
+
+func (match *bm25Match) SetVerbose() {
+	match.verbose = true
+}
+
+// score returns the candidates by descending BM25 score, normalised by the
+// score of the query against itself so that confidences are within [0, 1].
+// The candidates matched through synonyms are scored with the query expanded
+// with them.
+func (match *bm25Match) score(text string, candidates []string,
+	expansions map[string][]storage.Expansion) []questionAndScore {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	documents := make([]map[string]int, len(candidates))
+	lengths := make([]int, len(candidates))
+	var totalLength int
+	for i, candidate := range candidates {
+		tokens := match.analyzer.Tokens(candidate)
+		documents[i] = termFrequencies(tokens)
+		lengths[i] = len(tokens)
+		totalLength += len(tokens)
+	}
+	averageLength := float64(totalLength) / float64(len(candidates))
+	if averageLength == 0 {
+		return nil
+	}
+
+	type weightedQuery struct {
+		terms map[string]int
+		best  float64
+	}
+	queries := make(map[string]weightedQuery)
+	queryFor := func(text string) weightedQuery {
+		if query, ok := queries[text]; ok {
+			return query
+		}
+		tokens := match.analyzer.Tokens(text)
+		terms := termFrequencies(tokens)
+		query := weightedQuery{
+			terms: terms,
+			best:  match.bm25(terms, terms, len(tokens), averageLength),
+		}
+		queries[text] = query
+		return query
+	}
+
+	scores := make([]questionAndScore, 0, len(candidates))
+	for i, candidate := range candidates {
+		query := queryFor(expand(text, expansions[candidate]))
+		if query.best <= 0 {
+			continue
+		}
+		score := match.bm25(query.terms, documents[i], lengths[i], averageLength) / query.best
+		if score > 1 {
+			score = 1
+		}
+		scores = append(scores, questionAndScore{
+			question:   candidate,
+			score:      float32(score),
+			expansions: expansions[candidate],
+		})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].score > scores[j].score
+	})
+
+	return scores
+}
+
+func (match *bm25Match) bm25(query, document map[string]int, length int, averageLength float64) float64 {
+	var score float64
+	norm := match.k1 * (1 - match.b + match.b*float64(length)/averageLength)
+	for term := range query {
+		frequency := float64(document[term])
+		if frequency == 0 {
+			continue
+		}
+		score += match.analyzer.Idf(term) * frequency * (match.k1 + 1) / (frequency + norm)
+	}
+	return score
+}
+
+func termFrequencies(tokens []string) map[string]int {
+	frequencies := make(map[string]int, len(tokens))
+	for _, token := range tokens {
+		frequencies[token]++
+	}
+	return frequencies
+}
+
+func (fieldsAnalyzer) Tokens(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+func (fieldsAnalyzer) Idf(string) float64 {
+	return 1
+}
@@ -0,0 +1,92 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+func newTestStorage(questions map[string]string) storage.StorageAdapter {
+	store := storage.NewMemoryStorage()
+	id := 0
+	for question, answer := range questions {
+		id++
+		store.Update(question, []storage.Record{{Question: question, Answer: answer, CorpusId: id, Occurrence: 1}})
+	}
+	store.BuildIndex()
+	return store
+}
+
+func TestBM25Match_Process(t *testing.T) {
+	store := newTestStorage(map[string]string{
+		"how to reset my password?":          "use the portal",
+		"how to reset the printer?":          "turn it off and on",
+		"what is the password policy today?": "twelve characters",
+	})
+	match := NewBM25Match(store, 3, 0, 0)
+
+	answers := match.Process("reset password")
+	if len(answers) == 0 {
+		t.Fatal("expected answers")
+	}
+	if answers[0].Content != "use the portal" {
+		t.Errorf("expected the password reset answer first, got %v", answers)
+	}
+	for i, answer := range answers {
+		if answer.Confidence <= 0 || answer.Confidence > 1 {
+			t.Errorf("expected a normalised confidence, got %f", answer.Confidence)
+		}
+		if i > 0 && answer.Confidence > answers[i-1].Confidence {
+			t.Errorf("expected answers by descending confidence, got %v", answers)
+		}
+	}
+
+	answers = match.Process("how to reset the printer?")
+	if len(answers) != 1 || answers[0].Confidence != 1 || answers[0].Record.CorpusId == 0 {
+		t.Errorf("expected the exact match with full confidence, got %v", answers)
+	}
+}
+
+func TestBM25Match_TermImportance(t *testing.T) {
+	match := NewBM25Match(storage.NewMemoryStorage(), 3, 1.2, 0.75).(*bm25Match)
+	match.analyzer = weightedAnalyzer{"vpn": 5}
+
+	scores := match.score("vpn the", []string{"the the office", "vpn office"}, nil)
+	if len(scores) != 2 || scores[0].question != "vpn office" {
+		t.Errorf("expected the rare term to outweigh the common one, got %v", scores)
+	}
+}
+
+type weightedAnalyzer map[string]float64
+
+func (analyzer weightedAnalyzer) Tokens(text string) []string {
+	return fieldsAnalyzer{}.Tokens(text)
+}
+
+func (analyzer weightedAnalyzer) Idf(term string) float64 {
+	if idf, ok := analyzer[term]; ok {
+		return idf
+	}
+	return 0.1
+}
+
+func TestBM25Match_Synonyms(t *testing.T) {
+	store := newTestStorage(map[string]string{
+		"how do I sign in to the portal?": "use your badge",
+		"how to reset the printer?":       "turn it off and on",
+	})
+	store.(storage.Expanding).SetSynonyms(storage.NewSynonyms([][]string{{"登录", "sign in"}}), false)
+	match := NewBM25Match(store, 3, 0, 0)
+
+	answers := match.Process("portal 登录")
+	if len(answers) == 0 || answers[0].Content != "use your badge" {
+		t.Fatalf("expected the answer matched through the synonym, got %v", answers)
+	}
+	if expansions := answers[0].Expansions; len(expansions) != 1 || expansions[0].Synonym != "sign in" {
+		t.Errorf("expected the expansion to be reported, got %v", expansions)
+	}
+	if expected := match.Process("portal sign in"); answers[0].Confidence != expected[0].Confidence {
+		t.Errorf("expected the expanded query to score like its synonym, got %f and %f",
+			answers[0].Confidence, expected[0].Confidence)
+	}
+}
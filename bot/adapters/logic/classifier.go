@@ -0,0 +1,142 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"sync"
+)
+
+// IntentModelName is the name of the intent classifier in a storage.ModelStore.
+const IntentModelName = "intent"
+
+type (
+	// IntentClassifier is a multinomial naive Bayes classifier predicting the
+	// class of a question from its tokens, it is safe for concurrent use.
+	IntentClassifier struct {
+		mu    sync.RWMutex
+		model intentModel
+	}
+
+	intentModel struct {
+		Documents  int
+		Classes    map[string]*intentClass
+		Vocabulary map[string]bool
+	}
+
+	intentClass struct {
+		Documents   int
+		Tokens      int
+		Frequencies map[string]int
+	}
+
+	// IntentSample is a tokenized question of a class.
+	IntentSample struct {
+		Class  string
+		Tokens []string
+	}
+)
+
+func NewIntentClassifier() *IntentClassifier {
+	return &IntentClassifier{model: newIntentModel()}
+}
+
+func newIntentModel() intentModel {
+	return intentModel{
+		Classes:    make(map[string]*intentClass),
+		Vocabulary: make(map[string]bool),
+	}
+}
+
+// Train replaces the model with one trained from the samples, the samples
+// without a class are skipped.
+func (classifier *IntentClassifier) Train(samples []IntentSample) {
+	model := newIntentModel()
+	for _, sample := range samples {
+		if sample.Class == "" {
+			continue
+		}
+
+		class, ok := model.Classes[sample.Class]
+		if !ok {
+			class = &intentClass{Frequencies: make(map[string]int)}
+			model.Classes[sample.Class] = class
+		}
+		class.Documents++
+		model.Documents++
+		for _, token := range sample.Tokens {
+			class.Frequencies[token]++
+			class.Tokens++
+			model.Vocabulary[token] = true
+		}
+	}
+
+	classifier.mu.Lock()
+	classifier.model = model
+	classifier.mu.Unlock()
+}
+
+// Classes returns the number of classes known by the model.
+func (classifier *IntentClassifier) Classes() int {
+	classifier.mu.RLock()
+	defer classifier.mu.RUnlock()
+	return len(classifier.model.Classes)
+}
+
+// Predict returns the most likely class of the tokens with its posterior
+// probability, or no class when the model is empty.
+func (classifier *IntentClassifier) Predict(tokens []string) (string, float64) {
+	classifier.mu.RLock()
+	defer classifier.mu.RUnlock()
+
+	model := classifier.model
+	if model.Documents == 0 {
+		return "", 0
+	}
+
+	vocabulary := float64(len(model.Vocabulary))
+	scores := make(map[string]float64, len(model.Classes))
+	best, bestScore := "", math.Inf(-1)
+	for name, class := range model.Classes {
+		score := math.Log(float64(class.Documents) / float64(model.Documents))
+		for _, token := range tokens {
+			if !model.Vocabulary[token] {
+				continue
+			}
+			score += math.Log((float64(class.Frequencies[token]) + 1) / (float64(class.Tokens) + vocabulary))
+		}
+		scores[name] = score
+		if score > bestScore || score == bestScore && name < best {
+			best, bestScore = name, score
+		}
+	}
+
+	var total float64
+	for _, score := range scores {
+		total += math.Exp(score - bestScore)
+	}
+	return best, 1 / total
+}
+
+func (classifier *IntentClassifier) MarshalBinary() ([]byte, error) {
+	classifier.mu.RLock()
+	defer classifier.mu.RUnlock()
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(classifier.model); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (classifier *IntentClassifier) UnmarshalBinary(data []byte) error {
+	model := newIntentModel()
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&model); err != nil {
+		return err
+	}
+
+	classifier.mu.Lock()
+	classifier.model = model
+	classifier.mu.Unlock()
+	return nil
+}
@@ -17,18 +17,19 @@ const (
 
 type (
 	sourceAndTargets struct {
-		source  string
-		targets []string
+		source     string
+		targets    []string
+		expansions map[string][]storage.Expansion
 	}
 
 	questionAndScore struct {
-		question string
-		score    float32
+		question   string
+		score      float32
+		expansions []storage.Expansion
 	}
 
 	answerAndOccurrence struct {
-		question   string
-		answer     string
+		record     storage.Record
 		occurrence int
 	}
 
@@ -59,12 +60,18 @@ func (match *closestMatch) CanProcess(string) bool {
 }
 
 func (match *closestMatch) Process(text string, context ...string) []Answer {
-	if responses, ok := match.storage.Find(text, context...); ok {
+	return match.ProcessClass(text, "", context...)
+}
+
+// ProcessClass answers with the questions of the class only, of any class when
+// it is empty.
+func (match *closestMatch) ProcessClass(text, class string, context ...string) []Answer {
+	if responses, ok := find(match.storage, text, class, context...); ok {
 		fmt.Printf("Got response from find...")
 		return match.processExactMatch(responses)
 	} else {
 		fmt.Printf("Get similar match...")
-		return match.processSimilarMatch(text, context...)
+		return match.processSimilarMatch(text, class, context...)
 	}
 }
 
@@ -72,34 +79,40 @@ func (match *closestMatch) SetVerbose() {
 	match.verbose = true
 }
 
-func (match *closestMatch) processExactMatch(responses map[string]int) []Answer {
+func (match *closestMatch) processExactMatch(responses []storage.Record) []Answer {
+	return topAnswers(responses, match.tops)
+}
+
+// topAnswers returns the most frequent answers with full confidence.
+func topAnswers(responses []storage.Record, tops int) []Answer {
 	var top topOccurAnswers
 
-	for key, occurrence := range responses {
-		top.put(key, occurrence)
+	for _, record := range responses {
+		top.put(record, record.Occurrence)
 	}
 
 	sort.Slice(top.answers, func(i, j int) bool {
 		return top.answers[i].occurrence > top.answers[j].occurrence
 	})
 
-	tops := match.tops
 	if len(top.answers) < tops {
 		tops = len(top.answers)
 	}
 
 	answers := make([]Answer, tops)
 	for i := 0; i < tops; i++ {
-		answers[i].Content = top.answers[i].answer
+		answers[i].Content = top.answers[i].record.Answer
 		answers[i].Confidence = 1
+		answers[i].Record = top.answers[i].record
 	}
 
 	return answers
 }
 
-func (match *closestMatch) processSimilarMatch(text string, context ...string) []Answer {
+func (match *closestMatch) processSimilarMatch(text, class string, context ...string) []Answer {
+	text = normalize(match.storage, text)
 	fmt.Printf("Get similar to '%s'\n", text)
-	result, err := mr.MapReduce(generator(match, text), mapper(match), reducer(match))
+	result, err := mr.MapReduce(generator(match, text, class, context...), mapper(match), reducer(match))
 	if err != nil {
 		return nil
 	}
@@ -108,12 +121,14 @@ func (match *closestMatch) processSimilarMatch(text string, context ...string) [
 	slice := result.([]questionAndScore)
 	for _, each := range slice {
 		if each.score > 0 {
-			if responses, ok := match.storage.Find(each.question, context...); ok {
+			if responses, ok := find(match.storage, each.question, class, context...); ok {
 				matches := match.processExactMatch(responses)
 				if len(matches) > 0 {
 					answers = append(answers, Answer{
 						Content:    matches[0].Content,
 						Confidence: each.score,
+						Record:     matches[0].Record,
+						Expansions: each.expansions,
 					})
 				}
 			}
@@ -123,10 +138,10 @@ func (match *closestMatch) processSimilarMatch(text string, context ...string) [
 	return answers
 }
 
-func (top *topOccurAnswers) put(answer string, occurrence int) {
+func (top *topOccurAnswers) put(record storage.Record, occurrence int) {
 	if len(top.answers) < topAnswerSize {
 		top.answers = append(top.answers, &answerAndOccurrence{
-			answer:     answer,
+			record:     record,
 			occurrence: occurrence,
 		})
 	} else {
@@ -142,16 +157,17 @@ func (top *topOccurAnswers) put(answer string, occurrence int) {
 
 		if leastOccurrence < occurrence {
 			top.answers[leastIndex] = &answerAndOccurrence{
-				answer:     answer,
+				record:     record,
 				occurrence: occurrence,
 			}
 		}
 	}
 }
 
-func generator(match *closestMatch, text string) mr.GenerateFunc {
+func generator(match *closestMatch, text, class string, context ...string) mr.GenerateFunc {
 	return func(source chan<- interface{}) {
-		keys := match.storage.Search(text)
+		keys, expansions := search(match.storage, text)
+		keys = restrict(match.storage, keys, class, context...)
 		if match.verbose {
 			printMatches(keys)
 		}
@@ -159,8 +175,9 @@ func generator(match *closestMatch, text string) mr.GenerateFunc {
 		chunks := splitStrings(keys, chunkSize)
 		for _, chunk := range chunks {
 			source <- sourceAndTargets{
-				source:  text,
-				targets: chunk,
+				source:     text,
+				targets:    chunk,
+				expansions: expansions,
 			}
 		}
 	}
@@ -171,10 +188,17 @@ func mapper(match *closestMatch) mr.MapperFunc {
 		tops := newTopScoreQuestions(match.tops)
 		pair := data.(sourceAndTargets)
 		for i := range pair.targets {
+			expansions := pair.expansions[pair.targets[i]]
 			score := nlp.SimilarityForStrings(pair.source, pair.targets[i])
+			if len(expansions) > 0 {
+				if expanded := nlp.SimilarityForStrings(expand(pair.source, expansions), pair.targets[i]); expanded > score {
+					score = expanded
+				}
+			}
 			tops.add(questionAndScore{
-				question: pair.targets[i],
-				score:    score,
+				question:   pair.targets[i],
+				score:      score,
+				expansions: expansions,
 			})
 		}
 
@@ -1,8 +1,46 @@
 package logic
 
-type comboMatch struct {
-	matches []LogicAdapter
-}
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+type (
+	// comboMatch answers with the first adapter that can process a question.
+	comboMatch struct {
+		matches []LogicAdapter
+	}
+
+	// WeightedAdapter is an adapter of a merging combo, its confidences are
+	// weighted by Weight, 1 when it is not positive, and its answers are
+	// dropped when they take longer than Timeout, if it is positive.
+	WeightedAdapter struct {
+		Adapter LogicAdapter
+		Weight  float32
+		Timeout time.Duration
+	}
+
+	// mergeMatch runs every adapter that can process a question concurrently
+	// and merges their answers. Answers of the same corpus, rule or content
+	// are merged, their confidence is the weighted average of the confidences
+	// given by the adapters that answered in time.
+	mergeMatch struct {
+		tops     int
+		adapters []WeightedAdapter
+	}
+
+	adapterAnswers struct {
+		index   int
+		answers []Answer
+	}
+
+	mergedAnswer struct {
+		answer Answer
+		best   float32
+		score  float32
+	}
+)
 
 func NewComboMatch(matches ...LogicAdapter) LogicAdapter {
 	return &comboMatch{
@@ -28,8 +66,184 @@ func (match *comboMatch) Process(question string, context ...string) []Answer {
 	return nil
 }
 
+func (match *comboMatch) ProcessClass(question, class string, context ...string) []Answer {
+	for _, each := range match.matches {
+		if each.CanProcess(question) {
+			return processClass(each, question, class, context...)
+		}
+	}
+	return nil
+}
+
+// Explain explains the scores of the first adapter explaining them.
+func (match *comboMatch) Explain(question string, context ...string) ([]Explanation, bool) {
+	return explain(match.matches, question, context...)
+}
+
 func (match *comboMatch) SetVerbose() {
 	for _, each := range match.matches {
 		each.SetVerbose()
 	}
 }
+
+// NewMergeMatch merges the answers of the adapters, keeping the tops ones.
+func NewMergeMatch(tops int, adapters ...WeightedAdapter) LogicAdapter {
+	weighted := make([]WeightedAdapter, len(adapters))
+	for i, each := range adapters {
+		if each.Weight <= 0 {
+			each.Weight = 1
+		}
+		weighted[i] = each
+	}
+
+	return &mergeMatch{
+		tops:     tops,
+		adapters: weighted,
+	}
+}
+
+func (match *mergeMatch) CanProcess(question string) bool {
+	for _, each := range match.adapters {
+		if each.Adapter.CanProcess(question) {
+			return true
+		}
+	}
+	return false
+}
+
+func (match *mergeMatch) Process(question string, context ...string) []Answer {
+	return match.process(question, func(adapter LogicAdapter) []Answer {
+		return adapter.Process(question, context...)
+	})
+}
+
+func (match *mergeMatch) ProcessClass(question, class string, context ...string) []Answer {
+	return match.process(question, func(adapter LogicAdapter) []Answer {
+		return processClass(adapter, question, class, context...)
+	})
+}
+
+func (match *mergeMatch) process(question string, process func(LogicAdapter) []Answer) []Answer {
+	// both channels are buffered so that late adapters don't block
+	results := make(chan adapterAnswers, len(match.adapters))
+	timeouts := make(chan int, len(match.adapters))
+	pending := make(map[int]bool, len(match.adapters))
+	for i, each := range match.adapters {
+		if !each.Adapter.CanProcess(question) {
+			continue
+		}
+		pending[i] = true
+		go func(i int, adapter LogicAdapter) {
+			results <- adapterAnswers{index: i, answers: process(adapter)}
+		}(i, each.Adapter)
+
+		if each.Timeout > 0 {
+			i := i
+			timer := time.AfterFunc(each.Timeout, func() {
+				timeouts <- i
+			})
+			defer timer.Stop()
+		}
+	}
+
+	var answered []adapterAnswers
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			if pending[result.index] {
+				delete(pending, result.index)
+				answered = append(answered, result)
+			}
+		case i := <-timeouts:
+			delete(pending, i)
+		}
+	}
+
+	return match.merge(answered)
+}
+
+// Explain explains the scores of the first adapter explaining them.
+func (match *mergeMatch) Explain(question string, context ...string) ([]Explanation, bool) {
+	adapters := make([]LogicAdapter, len(match.adapters))
+	for i, each := range match.adapters {
+		adapters[i] = each.Adapter
+	}
+	return explain(adapters, question, context...)
+}
+
+func (match *mergeMatch) SetVerbose() {
+	for _, each := range match.adapters {
+		each.Adapter.SetVerbose()
+	}
+}
+
+func (match *mergeMatch) merge(answered []adapterAnswers) []Answer {
+	var totalWeight float32
+	merged := make(map[string]*mergedAnswer)
+	var order []string
+	for _, result := range answered {
+		weight := match.adapters[result.index].Weight
+		totalWeight += weight
+
+		// an adapter counts once for each answer
+		seen := make(map[string]bool)
+		for _, answer := range result.answers {
+			key := answerKey(answer)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			each, ok := merged[key]
+			if !ok {
+				each = &mergedAnswer{answer: answer}
+				merged[key] = each
+				order = append(order, key)
+			}
+			if answer.Confidence > each.best {
+				each.answer, each.best = answer, answer.Confidence
+			}
+			each.score += weight * answer.Confidence
+		}
+	}
+
+	answers := make([]Answer, 0, len(order))
+	for _, key := range order {
+		each := merged[key]
+		each.answer.Confidence = each.score / totalWeight
+		answers = append(answers, each.answer)
+	}
+	sort.SliceStable(answers, func(i, j int) bool {
+		return answers[i].Confidence > answers[j].Confidence
+	})
+
+	if match.tops > 0 && len(answers) > match.tops {
+		answers = answers[:match.tops]
+	}
+	return answers
+}
+
+// explain returns the explanations of the first adapter explaining its scores.
+func explain(adapters []LogicAdapter, question string, context ...string) ([]Explanation, bool) {
+	for _, each := range adapters {
+		if explaining, ok := each.(Explaining); ok {
+			if explanations, ok := explaining.Explain(question, context...); ok {
+				return explanations, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// answerKey identifies the answers to merge, by corpus, by rule or else by
+// content.
+func answerKey(answer Answer) string {
+	switch {
+	case answer.Record.CorpusId > 0:
+		return "corpus:" + strconv.Itoa(answer.Record.CorpusId)
+	case answer.Rule != "":
+		return "rule:" + answer.Rule
+	default:
+		return "content:" + answer.Content
+	}
+}
@@ -0,0 +1,71 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+type stubAdapter struct {
+	answers []Answer
+	delay   time.Duration
+	refuse  bool
+}
+
+func (adapter stubAdapter) CanProcess(string) bool {
+	return !adapter.refuse
+}
+
+func (adapter stubAdapter) Process(string, ...string) []Answer {
+	time.Sleep(adapter.delay)
+	return adapter.answers
+}
+
+func (stubAdapter) SetVerbose() {}
+
+func corpusAnswer(id int, confidence float32) Answer {
+	return Answer{
+		Content:    "answer",
+		Confidence: confidence,
+		Record:     storage.Record{CorpusId: id},
+	}
+}
+
+func TestMergeMatch_Process(t *testing.T) {
+	match := NewMergeMatch(5,
+		WeightedAdapter{Adapter: stubAdapter{answers: []Answer{corpusAnswer(1, 0.5), corpusAnswer(2, 1)}}, Weight: 3},
+		WeightedAdapter{Adapter: stubAdapter{answers: []Answer{corpusAnswer(1, 1), corpusAnswer(1, 0.9)}}},
+		WeightedAdapter{Adapter: stubAdapter{answers: []Answer{corpusAnswer(3, 1)}, refuse: true}},
+		WeightedAdapter{Adapter: stubAdapter{answers: []Answer{corpusAnswer(4, 1)}, delay: time.Second},
+			Timeout: 10 * time.Millisecond},
+	)
+
+	start := time.Now()
+	answers := match.Process("question")
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the slow adapter to time out, took %s", elapsed)
+	}
+
+	// corpus 2: 3*1/4, corpus 1: (3*0.5 + 1*1)/4
+	expected := map[int]float32{2: 0.75, 1: 0.625}
+	if len(answers) != len(expected) {
+		t.Fatalf("expected %d answers, got %v", len(expected), answers)
+	}
+	for i, id := range []int{2, 1} {
+		if answers[i].Record.CorpusId != id || answers[i].Confidence != expected[id] {
+			t.Errorf("expected corpus %d with %f at %d, got %v", id, expected[id], i, answers[i])
+		}
+	}
+}
+
+func TestComboMatch_FirstMatch(t *testing.T) {
+	match := NewComboMatch(
+		stubAdapter{answers: []Answer{corpusAnswer(1, 1)}, refuse: true},
+		stubAdapter{answers: []Answer{corpusAnswer(2, 0.5)}},
+		stubAdapter{answers: []Answer{corpusAnswer(3, 1)}},
+	)
+	if answers := match.Process("question"); len(answers) != 1 || answers[0].Record.CorpusId != 2 {
+		t.Errorf("expected the first adapter able to process to answer, got %v", answers)
+	}
+}
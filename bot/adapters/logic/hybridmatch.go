@@ -0,0 +1,216 @@
+package logic
+
+import (
+	"sort"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
+)
+
+const (
+	SignalSimilarity = "similarity"
+	SignalJaccard    = "jaccard"
+	SignalBM25       = "bm25"
+	SignalFeedback   = "feedback"
+)
+
+// DefaultHybridWeights is used when every weight of a project is zero.
+var DefaultHybridWeights = HybridWeights{
+	Similarity: 0.3,
+	Jaccard:    0.2,
+	BM25:       0.4,
+	Feedback:   0.1,
+}
+
+type (
+	// HybridWeights weights every signal of the hybrid adapter, the
+	// confidence is the weighted average of the signals.
+	HybridWeights struct {
+		Similarity float64 `json:"similarity"`
+		Jaccard    float64 `json:"jaccard"`
+		BM25       float64 `json:"bm25"`
+		Feedback   float64 `json:"feedback"`
+	}
+
+	// Signals holds the score of each signal, all of them are within [0, 1].
+	Signals map[string]float64
+
+	// FeedbackCounter returns how many times the answer of a corpus was
+	// accepted and rejected.
+	FeedbackCounter func(corpusId int) (accept, reject int)
+
+	// Explanation is a candidate of the hybrid adapter with its signals.
+	Explanation struct {
+		Question   string         `json:"question"`
+		Record     storage.Record `json:"record"`
+		Signals    Signals        `json:"signals"`
+		Confidence float32        `json:"confidence"`
+		// Expansions are the synonyms of the question found in the candidate.
+		Expansions []storage.Expansion `json:"expansions,omitempty"`
+	}
+
+	hybridMatch struct {
+		verbose  bool
+		storage  storage.StorageAdapter
+		bm25     *bm25Match
+		tops     int
+		weights  HybridWeights
+		feedback FeedbackCounter
+	}
+)
+
+// NewHybridMatch blends edit distance, token overlap, BM25 and the feedback
+// of the answers, feedback may be nil.
+func NewHybridMatch(store storage.StorageAdapter, tops int, weights HybridWeights, k1, b float64,
+	feedback FeedbackCounter) LogicAdapter {
+	if weights.total() <= 0 {
+		weights = DefaultHybridWeights
+	}
+
+	return &hybridMatch{
+		storage:  store,
+		bm25:     NewBM25Match(store, tops, k1, b).(*bm25Match),
+		tops:     tops,
+		weights:  weights,
+		feedback: feedback,
+	}
+}
+
+func (match *hybridMatch) CanProcess(string) bool {
+	return true
+}
+
+func (match *hybridMatch) Process(text string, context ...string) []Answer {
+	return match.ProcessClass(text, "", context...)
+}
+
+// ProcessClass ranks the questions of the class only, of any class when it is
+// empty.
+func (match *hybridMatch) ProcessClass(text, class string, context ...string) []Answer {
+	if responses, ok := find(match.storage, text, class, context...); ok {
+		return topAnswers(responses, match.tops)
+	}
+
+	var answers []Answer
+	for _, explanation := range match.explain(text, class, context...) {
+		if len(answers) >= match.tops || explanation.Confidence <= 0 {
+			break
+		}
+		answers = append(answers, Answer{
+			Content:    explanation.Record.Answer,
+			Confidence: explanation.Confidence,
+			Record:     explanation.Record,
+			Expansions: explanation.Expansions,
+		})
+	}
+	return answers
+}
+
+func (match *hybridMatch) SetVerbose() {
+	match.verbose = true
+	match.bm25.SetVerbose()
+}
+
+// Explain scores every candidate of Search, by descending confidence, so
+// that the weights can be tuned against labelled questions.
+func (match *hybridMatch) Explain(text string, context ...string) ([]Explanation, bool) {
+	return match.explain(text, "", context...), true
+}
+
+func (match *hybridMatch) explain(text, class string, context ...string) []Explanation {
+	text = normalize(match.storage, text)
+	candidates, expansions := search(match.storage, text)
+	candidates = restrict(match.storage, candidates, class, context...)
+	if match.verbose {
+		printMatches(candidates)
+	}
+
+	bm25 := make(map[string]float64, len(candidates))
+	for _, each := range match.bm25.score(text, candidates, expansions) {
+		bm25[each.question] = float64(each.score)
+	}
+
+	queryTokens := match.bm25.analyzer.Tokens(text)
+	var explanations []Explanation
+	for _, candidate := range candidates {
+		responses, ok := find(match.storage, candidate, class, context...)
+		if !ok {
+			continue
+		}
+		matches := topAnswers(responses, 1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		query, tokens := text, queryTokens
+		if len(expansions[candidate]) > 0 {
+			query = expand(text, expansions[candidate])
+			tokens = match.bm25.analyzer.Tokens(query)
+		}
+		signals := Signals{
+			SignalSimilarity: float64(nlp.SimilarityForStrings(query, candidate)),
+			SignalJaccard:    jaccard(tokens, match.bm25.analyzer.Tokens(candidate)),
+			SignalBM25:       bm25[candidate],
+			SignalFeedback:   match.feedbackSignal(matches[0].Record.CorpusId),
+		}
+		explanations = append(explanations, Explanation{
+			Question:   candidate,
+			Record:     matches[0].Record,
+			Signals:    signals,
+			Confidence: float32(match.weights.Combine(signals)),
+			Expansions: expansions[candidate],
+		})
+	}
+
+	sort.SliceStable(explanations, func(i, j int) bool {
+		return explanations[i].Confidence > explanations[j].Confidence
+	})
+	return explanations
+}
+
+// feedbackSignal is the smoothed ratio of accepted answers, answers without
+// feedback are neutral.
+func (match *hybridMatch) feedbackSignal(corpusId int) float64 {
+	if match.feedback == nil || corpusId <= 0 {
+		return 0.5
+	}
+
+	accept, reject := match.feedback(corpusId)
+	return float64(accept+1) / float64(accept+reject+2)
+}
+
+// Combine returns the weighted average of the signals.
+func (weights HybridWeights) Combine(signals Signals) float64 {
+	total := weights.total()
+	if total <= 0 {
+		return 0
+	}
+
+	return (weights.Similarity*signals[SignalSimilarity] +
+		weights.Jaccard*signals[SignalJaccard] +
+		weights.BM25*signals[SignalBM25] +
+		weights.Feedback*signals[SignalFeedback]) / total
+}
+
+func (weights HybridWeights) total() float64 {
+	return weights.Similarity + weights.Jaccard + weights.BM25 + weights.Feedback
+}
+
+func jaccard(source, target []string) float64 {
+	sourceSet := termFrequencies(source)
+	targetSet := termFrequencies(target)
+	union := len(sourceSet)
+	var intersection int
+	for term := range targetSet {
+		if _, ok := sourceSet[term]; ok {
+			intersection++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"testing"
+)
+
+func TestHybridMatch_Process(t *testing.T) {
+	store := newTestStorage(map[string]string{
+		"how to reset my password?":          "use the portal",
+		"how to reset the printer?":          "turn it off and on",
+		"what is the password policy today?": "twelve characters",
+	})
+	match := NewHybridMatch(store, 3, HybridWeights{}, 0, 0, nil)
+
+	answers := match.Process("reset my password")
+	if len(answers) == 0 || answers[0].Content != "use the portal" {
+		t.Fatalf("expected the password reset answer first, got %v", answers)
+	}
+	for i, answer := range answers {
+		if answer.Confidence <= 0 || answer.Confidence > 1 {
+			t.Errorf("expected a normalised confidence, got %f", answer.Confidence)
+		}
+		if i > 0 && answer.Confidence > answers[i-1].Confidence {
+			t.Errorf("expected answers by descending confidence, got %v", answers)
+		}
+	}
+}
+
+func TestHybridMatch_Explain(t *testing.T) {
+	store := newTestStorage(map[string]string{
+		"how to reset my password?": "use the portal",
+		"how to reset the printer?": "turn it off and on",
+	})
+	records, _ := store.Find("how to reset the printer?")
+	printer := records[0].CorpusId
+	feedback := func(corpusId int) (int, int) {
+		if corpusId == printer {
+			return 20, 0
+		}
+		return 0, 20
+	}
+
+	weights := HybridWeights{Feedback: 1}
+	match := NewHybridMatch(store, 3, weights, 0, 0, feedback).(*hybridMatch)
+	explanations, ok := match.Explain("how to reset")
+	if !ok || len(explanations) != 2 || explanations[0].Question != "how to reset the printer?" {
+		t.Fatalf("expected the accepted answer first, got %v", explanations)
+	}
+
+	for _, explanation := range explanations {
+		for _, signal := range []string{SignalSimilarity, SignalJaccard, SignalBM25, SignalFeedback} {
+			score, ok := explanation.Signals[signal]
+			if !ok || score < 0 || score > 1 {
+				t.Errorf("expected signal %s within [0, 1], got %v", signal, explanation.Signals)
+			}
+		}
+		if combined := float32(weights.Combine(explanation.Signals)); combined != explanation.Confidence {
+			t.Errorf("expected confidence %f to be the combined signals %f", explanation.Confidence, combined)
+		}
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	if score := jaccard([]string{"a", "b"}, []string{"b", "c"}); score != float64(1)/3 {
+		t.Errorf("expected 1/3, got %f", score)
+	}
+	if score := jaccard(nil, nil); score != 0 {
+		t.Errorf("expected 0 for empty sets, got %f", score)
+	}
+}
+
+func TestExplaining_Wrapped(t *testing.T) {
+	store := newTestStorage(map[string]string{
+		"how to reset my password?": "use the portal",
+	})
+	patterns, _ := NewPatternMatch(nil)
+	hybrid := NewHybridMatch(store, 3, HybridWeights{}, 0, 0, nil)
+
+	for _, adapter := range []LogicAdapter{
+		NewComboMatch(patterns, hybrid),
+		NewIntentMatch(NewComboMatch(patterns, hybrid), store, NewIntentClassifier(), false, 0),
+		NewMergeMatch(3, WeightedAdapter{Adapter: NewClosestMatch(store, 3)}, WeightedAdapter{Adapter: hybrid}),
+	} {
+		explaining, ok := adapter.(Explaining)
+		if !ok {
+			t.Fatalf("%T: expected the adapter to forward the explanations", adapter)
+		}
+		if explanations, ok := explaining.Explain("reset password"); !ok || len(explanations) != 1 {
+			t.Errorf("%T: expected the explanations of the hybrid adapter, got %v", adapter, explanations)
+		}
+	}
+
+	if _, ok := NewComboMatch(patterns, NewClosestMatch(store, 3)).(Explaining).Explain("reset password"); ok {
+		t.Error("expected no explanations without a hybrid adapter")
+	}
+}
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+// intentMatch classifies the questions before answering them with another
+// adapter, the answers report the predicted class and may be restricted to it.
+type intentMatch struct {
+	LogicAdapter
+	classifier     *IntentClassifier
+	analyzer       storage.Analyzer
+	restrict       bool
+	minProbability float64
+}
+
+// NewIntentMatch wraps the adapter with the classifier. When restrict is set
+// and the probability of the predicted class reaches minProbability, only the
+// questions of that class are ranked, unless none of them answers.
+func NewIntentMatch(adapter LogicAdapter, store storage.StorageAdapter, classifier *IntentClassifier,
+	restrict bool, minProbability float64) LogicAdapter {
+	analyzer, ok := store.(storage.Analyzer)
+	if !ok {
+		analyzer = fieldsAnalyzer{}
+	}
+
+	return &intentMatch{
+		LogicAdapter:   adapter,
+		classifier:     classifier,
+		analyzer:       analyzer,
+		restrict:       restrict,
+		minProbability: minProbability,
+	}
+}
+
+func (match *intentMatch) Classify(text string) (string, float64) {
+	return match.classifier.Predict(match.analyzer.Tokens(text))
+}
+
+// Explain explains the scores of the wrapped adapter, when it explains them.
+func (match *intentMatch) Explain(text string, context ...string) ([]Explanation, bool) {
+	if explaining, ok := match.LogicAdapter.(Explaining); ok {
+		return explaining.Explain(text, context...)
+	}
+	return nil, false
+}
+
+func (match *intentMatch) Process(text string, context ...string) []Answer {
+	class, probability := match.Classify(text)
+	if class == "" {
+		return match.LogicAdapter.Process(text, context...)
+	}
+
+	var answers []Answer
+	if match.restrict && probability >= match.minProbability {
+		answers = processClass(match.LogicAdapter, text, class, context...)
+	}
+	if len(answers) == 0 {
+		answers = match.LogicAdapter.Process(text, context...)
+	}
+
+	for i := range answers {
+		answers[i].Intent = class
+		answers[i].IntentProbability = float32(probability)
+	}
+	return answers
+}
@@ -0,0 +1,99 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+func trainedClassifier() *IntentClassifier {
+	classifier := NewIntentClassifier()
+	analyzer := fieldsAnalyzer{}
+	var samples []IntentSample
+	for question, class := range map[string]string{
+		"how do i pay my invoice":   "billing",
+		"the invoice is wrong":      "billing",
+		"refund my last payment":    "billing",
+		"the printer does not work": "hardware",
+		"my screen is broken":       "hardware",
+		"the keyboard is missing":   "hardware",
+		"what time is it":           "",
+	} {
+		samples = append(samples, IntentSample{Class: class, Tokens: analyzer.Tokens(question)})
+	}
+	classifier.Train(samples)
+	return classifier
+}
+
+func TestIntentClassifier_Predict(t *testing.T) {
+	classifier := trainedClassifier()
+	if classes := classifier.Classes(); classes != 2 {
+		t.Errorf("expected 2 classes, got %d", classes)
+	}
+
+	class, probability := classifier.Predict(fieldsAnalyzer{}.Tokens("wrong invoice payment"))
+	if class != "billing" || probability <= 0.5 || probability > 1 {
+		t.Errorf("expected billing, got %s with %f", class, probability)
+	}
+
+	data, err := classifier.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewIntentClassifier()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if restoredClass, restoredProbability := restored.Predict(fieldsAnalyzer{}.Tokens("wrong invoice payment")); restoredClass != class || restoredProbability != probability {
+		t.Errorf("expected the restored model to predict %s with %f, got %s with %f", class, probability, restoredClass, restoredProbability)
+	}
+
+	if class, _ := NewIntentClassifier().Predict([]string{"invoice"}); class != "" {
+		t.Errorf("expected no class from an empty model, got %s", class)
+	}
+}
+
+func TestIntentMatch_Process(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	for i, record := range []storage.Record{
+		{Question: "where is the printer invoice?", Answer: "in the billing portal", Class: "billing"},
+		{Question: "where is the printer?", Answer: "second floor", Class: "hardware"},
+	} {
+		record.CorpusId = i + 1
+		record.Occurrence = 1
+		store.Update(record.Question, []storage.Record{record})
+	}
+	store.BuildIndex()
+
+	match := NewIntentMatch(NewBM25Match(store, 2, 0, 0), store, trainedClassifier(), true, 0.5)
+	answers := match.Process("wrong printer invoice payment")
+	if len(answers) != 1 || answers[0].Record.Class != "billing" {
+		t.Fatalf("expected only the billing answer, got %v", answers)
+	}
+	if answers[0].Intent != "billing" || answers[0].IntentProbability <= 0.5 {
+		t.Errorf("expected the predicted intent, got %v", answers[0])
+	}
+}
+
+func TestIntentMatch_RestrictBeforeTops(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	for i, record := range []storage.Record{
+		{Question: "wrong printer invoice", Answer: "check the printer", Class: "hardware"},
+		{Question: "where is the printer invoice?", Answer: "in the billing portal", Class: "billing"},
+	} {
+		record.CorpusId = i + 1
+		record.Occurrence = 1
+		store.Update(record.Question, []storage.Record{record})
+	}
+	store.BuildIndex()
+
+	for _, adapter := range []LogicAdapter{NewBM25Match(store, 1, 0, 0), NewComboMatch(NewClosestMatch(store, 1))} {
+		if answers := adapter.Process("wrong printer invoice payment"); len(answers) != 1 || answers[0].Record.Class != "hardware" {
+			t.Fatalf("expected the hardware answer to rank first, got %v", answers)
+		}
+		match := NewIntentMatch(adapter, store, trainedClassifier(), true, 0.5)
+		if answers := match.Process("wrong printer invoice payment"); len(answers) != 1 || answers[0].Record.Class != "billing" {
+			t.Errorf("%T: expected the billing answer despite the tops, got %v", adapter, answers)
+		}
+	}
+}
@@ -1,10 +1,31 @@
 package logic
 
+import (
+	"strings"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
 type (
 	Answer struct {
 		//Title      string  `json:"title"`
-		Content    string  `json:"content"`
-		Confidence float32 `json:"confidence"`
+		Content    string         `json:"content"`
+		Confidence float32        `json:"confidence"`
+		Record     storage.Record `json:"record"`
+		// Intent is the class predicted for the question, when the adapter
+		// classifies questions.
+		Intent            string  `json:"intent,omitempty"`
+		IntentProbability float32 `json:"intent_probability,omitempty"`
+		// Rule is the name of the pattern rule giving the answer.
+		Rule string `json:"rule,omitempty"`
+		// Adapter is the name of the computational adapter giving the answer.
+		Adapter string `json:"adapter,omitempty"`
+		// Expansions are the synonyms of the question that matched the
+		// question of the answer.
+		Expansions []storage.Expansion `json:"expansions,omitempty"`
+		// Correction is the corrected question the answer was found for,
+		// when the question itself had no answer.
+		Correction string `json:"correction,omitempty"`
 	}
 
 	LogicAdapter interface {
@@ -12,4 +33,105 @@ type (
 		Process(string, ...string) []Answer
 		SetVerbose()
 	}
+
+	// Classifier is implemented by the adapters predicting the intent of a
+	// question, with its probability.
+	Classifier interface {
+		Classify(string) (string, float64)
+	}
+
+	// ClassRestricting is implemented by the adapters ranking only the
+	// candidates of a class when asked, so that the answers of the class
+	// are not cut off by the ones of other classes.
+	ClassRestricting interface {
+		ProcessClass(text, class string, context ...string) []Answer
+	}
+
+	// Explaining is implemented by the adapters telling the score of each
+	// signal of their candidates, so that the weights can be tuned against
+	// labelled questions. It returns false when no adapter explains them.
+	Explaining interface {
+		Explain(text string, context ...string) ([]Explanation, bool)
+	}
 )
+
+// processClass returns the answers of the adapter for the class, the answers
+// of the adapters that can't restrict their candidates are filtered instead.
+func processClass(adapter LogicAdapter, text, class string, context ...string) []Answer {
+	if restricting, ok := adapter.(ClassRestricting); ok {
+		return restricting.ProcessClass(text, class, context...)
+	}
+
+	var answers []Answer
+	for _, answer := range adapter.Process(text, context...) {
+		if answer.Record.Class == class {
+			answers = append(answers, answer)
+		}
+	}
+	return answers
+}
+
+// find returns the records of the key, only the ones of the class unless it is
+// empty.
+func find(store storage.StorageAdapter, key, class string, context ...string) ([]storage.Record, bool) {
+	records, ok := store.Find(key, context...)
+	if !ok || class == "" {
+		return records, ok
+	}
+
+	var restricted []storage.Record
+	for _, record := range records {
+		if record.Class == class {
+			restricted = append(restricted, record)
+		}
+	}
+	return restricted, len(restricted) > 0
+}
+
+// restrict keeps the candidates with records of the class, all of them when it
+// is empty.
+func restrict(store storage.StorageAdapter, candidates []string, class string, context ...string) []string {
+	if class == "" {
+		return candidates
+	}
+
+	var restricted []string
+	for _, candidate := range candidates {
+		if _, ok := find(store, candidate, class, context...); ok {
+			restricted = append(restricted, candidate)
+		}
+	}
+	return restricted
+}
+
+// normalize rewrites the text like the storage rewrites its keys, so that it
+// is compared with them in the same form.
+func normalize(store storage.StorageAdapter, text string) string {
+	if normalizing, ok := store.(storage.Normalizing); ok {
+		return normalizing.Normalize(text)
+	}
+	return text
+}
+
+// search returns the candidates of the text, with the expansions matching each
+// of them when the storage expands the queries with synonyms.
+func search(store storage.StorageAdapter, text string) ([]string, map[string][]storage.Expansion) {
+	if expanding, ok := store.(storage.Expanding); ok {
+		return expanding.SearchExpanded(text)
+	}
+	return store.Search(text), nil
+}
+
+// expand replaces the terms of the text with the synonyms found in a
+// candidate, so that the text is scored with the words of the candidate.
+func expand(text string, expansions []storage.Expansion) string {
+	if len(expansions) == 0 {
+		return text
+	}
+
+	text = strings.ToLower(text)
+	for _, expansion := range expansions {
+		text = strings.Replace(text, expansion.Term, expansion.Synonym, -1)
+	}
+	return text
+}
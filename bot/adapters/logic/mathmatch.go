@@ -0,0 +1,606 @@
+package logic
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	AdapterMath = "math"
+
+	maxExpressionLength = 256
+	maxExpressionDepth  = 16
+)
+
+var (
+	errInvalidExpression = errors.New("invalid expression")
+	errDivisionByZero    = errors.New("division by zero")
+
+	// mathPhrases are the words of the questions, operators map to their
+	// symbol and filler words to an empty string.
+	mathPhrases = map[string]string{
+		"plus":            "+",
+		"minus":           "-",
+		"times":           "*",
+		"x":               "*",
+		"multiplied by":   "*",
+		"divided by":      "/",
+		"over":            "/",
+		"mod":             "%",
+		"modulo":          "%",
+		"to the power of": "^",
+		"what":            "",
+		"what's":          "",
+		"whats":           "",
+		"is":              "",
+		"how much is":     "",
+		"calculate":       "",
+		"compute":         "",
+		"evaluate":        "",
+		"please":          "",
+		"equals":          "",
+		"equal to":        "",
+		"加上":              "+",
+		"加":               "+",
+		"减去":              "-",
+		"减":               "-",
+		"乘以":              "*",
+		"乘":               "*",
+		"除以":              "/",
+		"等于多少":            "",
+		"等于几":             "",
+		"等于":              "",
+		"是多少":             "",
+		"是几":              "",
+		"请问":              "",
+		"请":               "",
+		"帮我算":             "",
+		"计算":              "",
+		"算一下":             "",
+		"呢":               "",
+	}
+	mathPhraseKeys = sortedByLength(mathPhrases)
+
+	mathSymbols = map[rune]string{
+		'+': "+", '-': "-", '*': "*", '/': "/", '^': "^", '%': "%",
+		'×': "*", '÷': "/", '(': "(", ')': ")", '（': "(", '）': ")",
+	}
+
+	englishUnits = map[string]float64{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7,
+		"eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13,
+		"fourteen": 14, "fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	englishTens = map[string]float64{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70,
+		"eighty": 80, "ninety": 90,
+	}
+	englishScales = map[string]float64{
+		"hundred": 100, "thousand": 1e3, "million": 1e6, "billion": 1e9,
+	}
+
+	chineseDigits = map[rune]float64{
+		'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+		'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+	}
+	chineseUnits = map[rune]float64{
+		'十': 10, '百': 100, '千': 1e3, '万': 1e4, '亿': 1e8,
+	}
+)
+
+type (
+	// mathMatch evaluates the arithmetic of questions written with symbols,
+	// English or Chinese words, like "what is three plus 4" or "三加四等于几".
+	// Nothing is executed, the expression is parsed with + - * / % ^ and
+	// parentheses only.
+	mathMatch struct {
+		verbose bool
+	}
+
+	mathToken struct {
+		op    string
+		value float64
+	}
+
+	englishNumber struct {
+		total   float64
+		current float64
+		last    int
+	}
+
+	mathParser struct {
+		tokens    []mathToken
+		pos       int
+		depth     int
+		operators int
+	}
+)
+
+const (
+	englishNone = iota
+	englishUnit
+	englishTen
+	englishScale
+)
+
+func NewMathMatch() LogicAdapter {
+	return &mathMatch{}
+}
+
+func (match *mathMatch) CanProcess(text string) bool {
+	_, _, err := evaluateMath(text)
+	return err == nil
+}
+
+func (match *mathMatch) Process(text string, context ...string) []Answer {
+	expression, value, err := evaluateMath(text)
+	if err != nil {
+		return nil
+	}
+
+	return []Answer{{
+		Content:    expression + " = " + formatNumber(value, 10),
+		Confidence: 1,
+		Adapter:    AdapterMath,
+	}}
+}
+
+func (match *mathMatch) SetVerbose() {
+	match.verbose = true
+}
+
+// evaluateMath returns the expression of the text, written with symbols, and
+// its value. Texts without a binary operator are not expressions.
+func evaluateMath(text string) (string, float64, error) {
+	if len(text) > maxExpressionLength*utf8.UTFMax {
+		return "", 0, errInvalidExpression
+	}
+
+	tokens, err := mathTokens(strings.ToLower(strings.TrimSpace(text)))
+	if err != nil {
+		return "", 0, err
+	}
+	if len(tokens) > maxExpressionLength {
+		return "", 0, errInvalidExpression
+	}
+
+	parser := mathParser{tokens: tokens}
+	value, err := parser.expression()
+	if err != nil {
+		return "", 0, err
+	}
+	if parser.pos != len(tokens) || parser.operators == 0 {
+		return "", 0, errInvalidExpression
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", 0, errInvalidExpression
+	}
+
+	return formatTokens(tokens), value, nil
+}
+
+// mathTokens splits the text into numbers and operators, it fails on any word
+// that is neither a number, an operator nor a filler word.
+func mathTokens(text string) ([]mathToken, error) {
+	var tokens []mathToken
+	var english englishNumber
+	flush := func() {
+		if english.last != englishNone {
+			tokens = append(tokens, mathToken{value: english.value()})
+			english = englishNumber{}
+		}
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case unicode.IsSpace(r) || strings.ContainsRune("?？=＝,，!！。", r):
+			i += size
+		case r == '.' && (i+1 == len(text) || text[i+1] < '0' || text[i+1] > '9'):
+			i += size
+		case r >= '0' && r <= '9' || r == '.':
+			flush()
+			end := i
+			for end < len(text) && (text[end] >= '0' && text[end] <= '9' || text[end] == '.') {
+				end++
+			}
+			value, err := strconv.ParseFloat(text[i:end], 64)
+			if err != nil {
+				return nil, errInvalidExpression
+			}
+			tokens = append(tokens, mathToken{value: value})
+			i = end
+		case r == '-' && english.last == englishTen && i+1 < len(text) && unicode.IsLetter(rune(text[i+1])):
+			// "twenty-one"
+			i += size
+		case mathSymbols[r] != "":
+			flush()
+			tokens = append(tokens, mathToken{op: mathSymbols[r]})
+			i += size
+		default:
+			if phrase, op, ok := matchPhrase(text[i:]); ok {
+				flush()
+				if op != "" {
+					tokens = append(tokens, mathToken{op: op})
+				}
+				i += len(phrase)
+				continue
+			}
+
+			if value, n, ok := chineseNumber(text[i:]); ok {
+				flush()
+				tokens = append(tokens, mathToken{value: value})
+				i += n
+				continue
+			}
+
+			if !unicode.IsLetter(r) {
+				return nil, errInvalidExpression
+			}
+			end := i
+			for end < len(text) {
+				next, n := utf8.DecodeRuneInString(text[end:])
+				if !unicode.IsLetter(next) || next > unicode.MaxASCII {
+					break
+				}
+				end += n
+			}
+			word := text[i:end]
+			switch {
+			case english.add(word):
+			case isEnglishNumber(word):
+				flush()
+				english.add(word)
+			case word == "and" && english.last != englishNone:
+				// "one hundred and five"
+			default:
+				return nil, errInvalidExpression
+			}
+			i = end
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// matchPhrase returns the longest phrase the text starts with, English
+// phrases must end on a word boundary.
+func matchPhrase(text string) (string, string, bool) {
+	for _, phrase := range mathPhraseKeys {
+		if !strings.HasPrefix(text, phrase) {
+			continue
+		}
+		last, _ := utf8.DecodeLastRuneInString(phrase)
+		if last <= unicode.MaxASCII && len(text) > len(phrase) {
+			next, _ := utf8.DecodeRuneInString(text[len(phrase):])
+			if unicode.IsLetter(next) {
+				continue
+			}
+		}
+		return phrase, mathPhrases[phrase], true
+	}
+	return "", "", false
+}
+
+// chineseNumber parses the Chinese number the text starts with, like "三百零五"
+// or "三点五", and returns its length in bytes.
+func chineseNumber(text string) (float64, int, bool) {
+	var runes []rune
+	var n int
+	for n < len(text) {
+		r, size := utf8.DecodeRuneInString(text[n:])
+		_, digit := chineseDigits[r]
+		_, unit := chineseUnits[r]
+		if !digit && !unit && r != '点' {
+			break
+		}
+		runes = append(runes, r)
+		n += size
+	}
+	// a point is only a decimal point between numbers, like in "三点五"
+	for len(runes) > 0 && runes[len(runes)-1] == '点' {
+		runes = runes[:len(runes)-1]
+		n -= utf8.RuneLen('点')
+	}
+	if len(runes) == 0 || runes[0] == '点' {
+		return 0, 0, false
+	}
+
+	integer, fraction := runes, []rune(nil)
+	for i, r := range runes {
+		if r == '点' {
+			integer, fraction = runes[:i], runes[i+1:]
+			break
+		}
+	}
+
+	value, ok := chineseInteger(integer)
+	if !ok {
+		return 0, 0, false
+	}
+	scale := 0.1
+	for _, r := range fraction {
+		digit, ok := chineseDigits[r]
+		if !ok {
+			return 0, 0, false
+		}
+		value += digit * scale
+		scale /= 10
+	}
+	return value, n, true
+}
+
+func chineseInteger(runes []rune) (float64, bool) {
+	hasUnit := false
+	for _, r := range runes {
+		if _, ok := chineseUnits[r]; ok {
+			hasUnit = true
+			break
+		}
+	}
+
+	// digits only, like "一二三"
+	if !hasUnit {
+		var value float64
+		for _, r := range runes {
+			value = value*10 + chineseDigits[r]
+		}
+		return value, true
+	}
+
+	var total, section, number float64
+	for _, r := range runes {
+		if digit, ok := chineseDigits[r]; ok {
+			number = digit
+			continue
+		}
+
+		switch unit := chineseUnits[r]; unit {
+		case 1e4:
+			total += (section + number) * unit
+			section, number = 0, 0
+		case 1e8:
+			total = (total + section + number) * unit
+			section, number = 0, 0
+		default:
+			if number == 0 {
+				number = 1
+			}
+			section += number * unit
+			number = 0
+		}
+	}
+	return total + section + number, true
+}
+
+func isEnglishNumber(word string) bool {
+	_, unit := englishUnits[word]
+	_, ten := englishTens[word]
+	_, scale := englishScales[word]
+	return unit || ten || scale
+}
+
+// add appends a word to the number, it returns false when the word does not
+// continue the number, like "four" after "three".
+func (number *englishNumber) add(word string) bool {
+	if value, ok := englishUnits[word]; ok {
+		if number.last == englishUnit || number.last == englishTen && value >= 10 {
+			return false
+		}
+		number.current += value
+		number.last = englishUnit
+		return true
+	}
+
+	if value, ok := englishTens[word]; ok {
+		if number.last == englishUnit || number.last == englishTen {
+			return false
+		}
+		number.current += value
+		number.last = englishTen
+		return true
+	}
+
+	if value, ok := englishScales[word]; ok {
+		if number.last == englishNone {
+			return false
+		}
+		if number.current == 0 {
+			number.current = 1
+		}
+		if value == 100 {
+			number.current *= value
+		} else {
+			number.total += number.current * value
+			number.current = 0
+		}
+		number.last = englishScale
+		return true
+	}
+
+	return false
+}
+
+func (number englishNumber) value() float64 {
+	return number.total + number.current
+}
+
+func (parser *mathParser) expression() (float64, error) {
+	value, err := parser.term()
+	if err != nil {
+		return 0, err
+	}
+
+	for parser.peek("+") || parser.peek("-") {
+		op := parser.next().op
+		parser.operators++
+		right, err := parser.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			value += right
+		} else {
+			value -= right
+		}
+	}
+	return value, nil
+}
+
+func (parser *mathParser) term() (float64, error) {
+	value, err := parser.unary()
+	if err != nil {
+		return 0, err
+	}
+
+	for parser.peek("*") || parser.peek("/") || parser.peek("%") {
+		op := parser.next().op
+		parser.operators++
+		right, err := parser.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "*":
+			value *= right
+		case "/":
+			if right == 0 {
+				return 0, errDivisionByZero
+			}
+			value /= right
+		case "%":
+			if right == 0 {
+				return 0, errDivisionByZero
+			}
+			value = math.Mod(value, right)
+		}
+	}
+	return value, nil
+}
+
+func (parser *mathParser) unary() (float64, error) {
+	if parser.peek("-") || parser.peek("+") {
+		negative := parser.next().op == "-"
+		value, err := parser.unary()
+		if negative {
+			value = -value
+		}
+		return value, err
+	}
+	return parser.power()
+}
+
+// power is right associative and binds tighter than the unary minus, so that
+// -2^2 is -4.
+func (parser *mathParser) power() (float64, error) {
+	value, err := parser.primary()
+	if err != nil {
+		return 0, err
+	}
+
+	if parser.peek("^") {
+		parser.next()
+		parser.operators++
+		exponent, err := parser.unary()
+		if err != nil {
+			return 0, err
+		}
+		value = math.Pow(value, exponent)
+	}
+	return value, nil
+}
+
+func (parser *mathParser) primary() (float64, error) {
+	if parser.pos >= len(parser.tokens) {
+		return 0, errInvalidExpression
+	}
+
+	token := parser.next()
+	switch token.op {
+	case "":
+		return token.value, nil
+	case "(":
+		if parser.depth >= maxExpressionDepth {
+			return 0, errInvalidExpression
+		}
+		parser.depth++
+		value, err := parser.expression()
+		parser.depth--
+		if err != nil {
+			return 0, err
+		}
+		if !parser.peek(")") {
+			return 0, errInvalidExpression
+		}
+		parser.next()
+		return value, nil
+	default:
+		return 0, errInvalidExpression
+	}
+}
+
+func (parser *mathParser) peek(op string) bool {
+	return parser.pos < len(parser.tokens) && parser.tokens[parser.pos].op == op
+}
+
+func (parser *mathParser) next() mathToken {
+	token := parser.tokens[parser.pos]
+	parser.pos++
+	return token
+}
+
+// formatTokens writes the expression with symbols, like "3 + (4 * 2)".
+func formatTokens(tokens []mathToken) string {
+	var builder strings.Builder
+	for i, token := range tokens {
+		if i > 0 && token.op != ")" && tokens[i-1].op != "(" && !isUnary(tokens, i-1) {
+			builder.WriteByte(' ')
+		}
+		if token.op == "" {
+			builder.WriteString(formatNumber(token.value, 10))
+		} else {
+			builder.WriteString(token.op)
+		}
+	}
+	return builder.String()
+}
+
+// isUnary tells whether the operator at i is a sign.
+func isUnary(tokens []mathToken, i int) bool {
+	if tokens[i].op != "-" && tokens[i].op != "+" {
+		return false
+	}
+	return i == 0 || tokens[i-1].op != "" && tokens[i-1].op != ")"
+}
+
+// formatNumber rounds the value to the given decimals, without trailing zeros.
+func formatNumber(value float64, decimals int) string {
+	if math.Abs(value) < 1e15 {
+		scale := math.Pow10(decimals)
+		value = math.Round(value*scale) / scale
+	}
+	if value == 0 {
+		value = 0 // no negative zero
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func sortedByLength(phrases map[string]string) []string {
+	keys := make([]string, 0, len(phrases))
+	for key := range phrases {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,47 @@
+package logic
+
+import "testing"
+
+func TestMathMatch_Process(t *testing.T) {
+	match := NewMathMatch()
+	for question, answer := range map[string]string{
+		"1 + 2 * 3":                       "1 + 2 * 3 = 7",
+		"what is (1 + 2) * 3?":            "(1 + 2) * 3 = 9",
+		"What is three plus 4?":           "3 + 4 = 7",
+		"twenty-one divided by seven":     "21 / 7 = 3",
+		"one hundred and five minus five": "105 - 5 = 100",
+		"2 to the power of 10":            "2 ^ 10 = 1024",
+		"-2^2":                            "-2 ^ 2 = -4",
+		"0.1 + 0.2":                       "0.1 + 0.2 = 0.3",
+		"三加四等于几":                          "3 + 4 = 7",
+		"请问一百二十乘以三是多少？":                   "120 * 3 = 360",
+		"十除以四":                            "10 / 4 = 2.5",
+		"三点五减一万":                          "3.5 - 10000 = -9996.5",
+	} {
+		if !match.CanProcess(question) {
+			t.Errorf("expected '%s' to be processed", question)
+			continue
+		}
+		answers := match.Process(question)
+		if len(answers) != 1 || answers[0].Content != answer || answers[0].Adapter != AdapterMath {
+			t.Errorf("expected '%s' to be answered '%s', got %v", question, answer, answers)
+		}
+	}
+
+	for _, question := range []string{
+		"",
+		"42",
+		"what is the time",
+		"room 3 is next to the lift",
+		"1 / 0",
+		"(1 + 2",
+		"1 + + ",
+		"three four plus one",
+		"你好",
+		"今天几点",
+	} {
+		if match.CanProcess(question) {
+			t.Errorf("expected '%s' not to be processed", question)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package logic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// PatternRule answers the questions matching its pattern, containing every
+	// required keyword and none of the excluded ones. Keywords are matched
+	// case-insensitively, rules with a higher priority are tried first.
+	PatternRule struct {
+		Name     string   `json:"name" yaml:"name"`
+		Pattern  string   `json:"pattern" yaml:"pattern"`
+		Required []string `json:"required" yaml:"required"`
+		Excluded []string `json:"excluded" yaml:"excluded"`
+		Answer   string   `json:"answer" yaml:"answer"`
+		Class    string   `json:"class" yaml:"class"`
+		Priority int      `json:"priority" yaml:"priority"`
+	}
+
+	patternRules struct {
+		Rules []PatternRule `json:"rules" yaml:"rules"`
+	}
+
+	compiledRule struct {
+		PatternRule
+		pattern *regexp.Regexp
+	}
+
+	// patternMatch gives deterministic answers from rules, it only processes
+	// the questions one of its rules matches so that it goes in front of
+	// fuzzy adapters in a comboMatch.
+	patternMatch struct {
+		mu      sync.RWMutex
+		verbose bool
+		rules   []compiledRule
+	}
+
+	// RuleSetter is implemented by the adapters whose rules can be replaced.
+	RuleSetter interface {
+		SetRules([]PatternRule) error
+	}
+)
+
+func NewPatternMatch(rules []PatternRule) (LogicAdapter, error) {
+	match := &patternMatch{}
+	if err := match.SetRules(rules); err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// LoadPatternRules reads the rules of a YAML file with a "rules" list.
+func LoadPatternRules(file string) ([]PatternRule, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules patternRules
+	if err := yaml.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %s", file, err.Error())
+	}
+	return rules.Rules, nil
+}
+
+// SetRules replaces the rules, nothing changes when one of them is invalid.
+func (match *patternMatch) SetRules(rules []PatternRule) error {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Answer == "" {
+			return fmt.Errorf("rule '%s' has no answer", rule.Name)
+		}
+		if rule.Pattern == "" && len(rule.Required) == 0 {
+			return fmt.Errorf("rule '%s' needs a pattern or required keywords", rule.Name)
+		}
+
+		each := compiledRule{PatternRule: rule}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return fmt.Errorf("rule '%s' has an invalid pattern: %s", rule.Name, err.Error())
+			}
+			each.pattern = pattern
+		}
+		each.Required = lowerKeywords(rule.Required)
+		each.Excluded = lowerKeywords(rule.Excluded)
+		compiled = append(compiled, each)
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].Priority > compiled[j].Priority
+	})
+
+	match.mu.Lock()
+	match.rules = compiled
+	match.mu.Unlock()
+	return nil
+}
+
+func (match *patternMatch) CanProcess(question string) bool {
+	_, ok := match.find(question)
+	return ok
+}
+
+func (match *patternMatch) Process(question string, context ...string) []Answer {
+	rule, ok := match.find(question)
+	if !ok {
+		return nil
+	}
+	if match.verbose {
+		fmt.Printf("Matched rule '%s'\n", rule.Name)
+	}
+
+	return []Answer{{
+		Content:    rule.Answer,
+		Confidence: 1,
+		Rule:       rule.Name,
+		Record: storage.Record{
+			Question: question,
+			Answer:   rule.Answer,
+			Class:    rule.Class,
+		},
+	}}
+}
+
+func (match *patternMatch) SetVerbose() {
+	match.verbose = true
+}
+
+func (match *patternMatch) find(question string) (compiledRule, bool) {
+	match.mu.RLock()
+	defer match.mu.RUnlock()
+
+	lower := strings.ToLower(question)
+	for _, rule := range match.rules {
+		if rule.matches(question, lower) {
+			return rule, true
+		}
+	}
+	return compiledRule{}, false
+}
+
+func (rule compiledRule) matches(question, lower string) bool {
+	if rule.pattern != nil && !rule.pattern.MatchString(question) {
+		return false
+	}
+	for _, keyword := range rule.Required {
+		if !strings.Contains(lower, keyword) {
+			return false
+		}
+	}
+	for _, keyword := range rule.Excluded {
+		if strings.Contains(lower, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+func lowerKeywords(keywords []string) []string {
+	var lowered []string
+	for _, keyword := range keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			lowered = append(lowered, keyword)
+		}
+	}
+	return lowered
+}
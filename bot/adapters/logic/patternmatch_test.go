@@ -0,0 +1,81 @@
+package logic
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestPatternMatch_Process(t *testing.T) {
+	rules, err := loadTestRules(t, `
+rules:
+  - name: greeting
+    pattern: "^(hi|hello)\\b"
+    answer: Hello!
+  - name: escalation
+    required: [human, agent]
+    excluded: [not]
+    answer: Connecting you to an agent.
+    priority: 10
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match, err := NewPatternMatch(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for question, rule := range map[string]string{
+		"hello there":                      "greeting",
+		"hi, I want a HUMAN Agent":         "escalation",
+		"I do not want a human agent":      "",
+		"what is the weather like, hello?": "",
+	} {
+		if match.CanProcess(question) != (rule != "") {
+			t.Errorf("expected '%s' to match rule '%s'", question, rule)
+			continue
+		}
+		if rule == "" {
+			continue
+		}
+		answers := match.Process(question)
+		if len(answers) != 1 || answers[0].Rule != rule || answers[0].Confidence != 1 {
+			t.Errorf("expected '%s' to be answered by rule '%s', got %v", question, rule, answers)
+		}
+	}
+}
+
+func TestPatternMatch_InvalidRules(t *testing.T) {
+	for _, rule := range []PatternRule{
+		{Name: "no answer", Pattern: "a"},
+		{Name: "no condition", Answer: "a"},
+		{Name: "broken", Pattern: "(", Answer: "a"},
+	} {
+		if _, err := NewPatternMatch([]PatternRule{rule}); err == nil {
+			t.Errorf("expected rule '%s' to be rejected", rule.Name)
+		}
+	}
+}
+
+func TestComboMatch_PatternFirst(t *testing.T) {
+	store := newTestStorage(map[string]string{"hello, how are you?": "fine"})
+	patterns, _ := NewPatternMatch([]PatternRule{{Name: "greeting", Pattern: "^hello", Answer: "Hello!"}})
+	match := NewComboMatch(patterns, NewClosestMatch(store, 1))
+
+	if answers := match.Process("hello, how are you?"); len(answers) != 1 || answers[0].Content != "Hello!" {
+		t.Errorf("expected the rule to answer first, got %v", answers)
+	}
+	if answers := match.Process("how are you?"); len(answers) != 1 || answers[0].Content != "fine" {
+		t.Errorf("expected the closest match to answer, got %v", answers)
+	}
+}
+
+func loadTestRules(t *testing.T, content string) ([]PatternRule, error) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := ioutil.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return LoadPatternRules(file)
+}
@@ -0,0 +1,81 @@
+package logic
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const AdapterTime = "time"
+
+var (
+	englishTimeQuestion = regexp.MustCompile(`^(?:what time is it|what(?:'s| is) the (?:current )?time|` +
+		`(?:do you know|tell me) the time)(?: now| right now)?\s*[?.!]*$`)
+	englishDateQuestion = regexp.MustCompile(`^(?:what day is (?:it|today)|what date is (?:it|today)|` +
+		`what(?:'s| is) (?:the date|today's date|the day|today))(?: today)?\s*[?.!]*$`)
+	chineseTimeQuestion = regexp.MustCompile(`^(?:请问)?(?:现在)?(?:是)?(?:几点(?:钟)?|什么时间)(?:了)?(?:呢|啊)?[?？。]*$`)
+	chineseDateQuestion = regexp.MustCompile(`^(?:请问)?(?:今天|今日)(?:是)?(?:几号|几月几[号日]|星期几|礼拜几|周几|` +
+		`什么日子|的日期|日期)(?:呢|啊)?[?？。]*$`)
+
+	chineseWeekdays = []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+)
+
+// timeMatch answers the questions asking the time or the date, in English or
+// Chinese, with the clock of its location.
+type timeMatch struct {
+	verbose  bool
+	location *time.Location
+	now      func() time.Time
+}
+
+// NewTimeMatch creates a time adapter answering in the location, the local
+// time when it is nil.
+func NewTimeMatch(location *time.Location) LogicAdapter {
+	if location == nil {
+		location = time.Local
+	}
+
+	return &timeMatch{
+		location: location,
+		now:      time.Now,
+	}
+}
+
+func (match *timeMatch) CanProcess(text string) bool {
+	return match.answer(text) != ""
+}
+
+func (match *timeMatch) Process(text string, context ...string) []Answer {
+	content := match.answer(text)
+	if content == "" {
+		return nil
+	}
+
+	return []Answer{{
+		Content:    content,
+		Confidence: 1,
+		Adapter:    AdapterTime,
+	}}
+}
+
+func (match *timeMatch) SetVerbose() {
+	match.verbose = true
+}
+
+func (match *timeMatch) answer(text string) string {
+	text = strings.ToLower(strings.TrimSpace(strings.Replace(text, "’", "'", -1)))
+	now := match.now().In(match.location)
+	switch {
+	case englishTimeQuestion.MatchString(text):
+		return "It is " + now.Format("15:04 MST") + "."
+	case englishDateQuestion.MatchString(text):
+		return "Today is " + now.Format("Monday, January 2, 2006") + "."
+	case chineseTimeQuestion.MatchString(text):
+		return fmt.Sprintf("现在是%d点%02d分", now.Hour(), now.Minute())
+	case chineseDateQuestion.MatchString(text):
+		return fmt.Sprintf("今天是%d年%d月%d日，%s", now.Year(), now.Month(), now.Day(), chineseWeekdays[now.Weekday()])
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeMatch_Process(t *testing.T) {
+	match := NewTimeMatch(time.FixedZone("CST", 8*3600)).(*timeMatch)
+	match.now = func() time.Time {
+		return time.Date(2020, 1, 2, 7, 4, 0, 0, time.UTC)
+	}
+
+	for question, answer := range map[string]string{
+		"What time is it?":    "It is 15:04 CST.",
+		"what’s the time now": "It is 15:04 CST.",
+		"What day is today?":  "Today is Thursday, January 2, 2020.",
+		"现在几点了？":              "现在是15点04分",
+		"今天是星期几":              "今天是2020年1月2日，星期四",
+	} {
+		if !match.CanProcess(question) {
+			t.Errorf("expected '%s' to be processed", question)
+			continue
+		}
+		answers := match.Process(question)
+		if len(answers) != 1 || answers[0].Content != answer || answers[0].Adapter != AdapterTime {
+			t.Errorf("expected '%s' to be answered '%s', got %v", question, answer, answers)
+		}
+	}
+
+	for _, question := range []string{
+		"what time does the shop open?",
+		"what is the date of the launch",
+		"几点开门",
+	} {
+		if match.CanProcess(question) {
+			t.Errorf("expected '%s' not to be processed", question)
+		}
+	}
+}
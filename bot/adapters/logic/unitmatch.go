@@ -0,0 +1,142 @@
+package logic
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const AdapterUnit = "unit"
+
+const (
+	dimensionLength      = "length"
+	dimensionMass        = "mass"
+	dimensionTemperature = "temperature"
+	dimensionVolume      = "volume"
+)
+
+// measureUnit converts to the base unit of its dimension as
+// value*factor + offset.
+type measureUnit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+var (
+	measureUnits = map[string]measureUnit{}
+
+	unitConversion        *regexp.Regexp
+	reverseUnitConversion *regexp.Regexp
+)
+
+func init() {
+	units := []struct {
+		measureUnit
+		names []string
+	}{
+		{measureUnit{dimensionLength, 1, 0}, []string{"m", "meter", "meters", "metre", "metres", "米"}},
+		{measureUnit{dimensionLength, 1000, 0}, []string{"km", "kilometer", "kilometers", "kilometre", "kilometres", "公里", "千米"}},
+		{measureUnit{dimensionLength, 0.01, 0}, []string{"cm", "centimeter", "centimeters", "centimetre", "centimetres", "厘米"}},
+		{measureUnit{dimensionLength, 0.001, 0}, []string{"mm", "millimeter", "millimeters", "millimetre", "millimetres", "毫米"}},
+		{measureUnit{dimensionLength, 1609.344, 0}, []string{"mi", "mile", "miles", "英里"}},
+		{measureUnit{dimensionLength, 0.9144, 0}, []string{"yd", "yard", "yards", "码"}},
+		{measureUnit{dimensionLength, 0.3048, 0}, []string{"ft", "foot", "feet", "英尺"}},
+		{measureUnit{dimensionLength, 0.0254, 0}, []string{"in", "inch", "inches", "英寸"}},
+		{measureUnit{dimensionMass, 1, 0}, []string{"kg", "kilogram", "kilograms", "公斤", "千克"}},
+		{measureUnit{dimensionMass, 0.001, 0}, []string{"g", "gram", "grams", "克"}},
+		{measureUnit{dimensionMass, 0.45359237, 0}, []string{"lb", "lbs", "pound", "pounds", "磅"}},
+		{measureUnit{dimensionMass, 0.028349523125, 0}, []string{"oz", "ounce", "ounces", "盎司"}},
+		{measureUnit{dimensionMass, 0.5, 0}, []string{"斤"}},
+		{measureUnit{dimensionTemperature, 1, 273.15}, []string{"c", "°c", "celsius", "摄氏度"}},
+		{measureUnit{dimensionTemperature, 5.0 / 9, 273.15 - 32*5.0/9}, []string{"f", "°f", "fahrenheit", "华氏度"}},
+		{measureUnit{dimensionTemperature, 1, 0}, []string{"k", "kelvin", "开尔文"}},
+		{measureUnit{dimensionVolume, 1, 0}, []string{"l", "liter", "liters", "litre", "litres", "升"}},
+		{measureUnit{dimensionVolume, 0.001, 0}, []string{"ml", "milliliter", "milliliters", "millilitre", "millilitres", "毫升"}},
+		{measureUnit{dimensionVolume, 3.785411784, 0}, []string{"gal", "gallon", "gallons", "加仑"}},
+	}
+
+	names := make(map[string]string)
+	for _, unit := range units {
+		for _, name := range unit.names {
+			measureUnits[name] = unit.measureUnit
+			names[regexp.QuoteMeta(name)] = ""
+		}
+	}
+
+	// longest names first, so that "mm" is not read as "m"
+	pattern := "(" + strings.Join(sortedByLength(names), "|") + ")"
+	number := `(-?\d+(?:\.\d+)?)`
+	unitConversion = regexp.MustCompile(`^(?:(?:what is|what's|convert|please convert|把|请把)\s*)?` +
+		number + `\s*` + pattern + `\s*(?:to|in|into|as|=|等于|是|换算成|转换成|换成|合)\s*(?:多少)?\s*` +
+		pattern + `\s*[?？.。]*$`)
+	reverseUnitConversion = regexp.MustCompile(`^how many\s+` + pattern + `\s+(?:are\s+)?in\s+` +
+		number + `\s*` + pattern + `\s*[?.]*$`)
+}
+
+// unitMatch converts a quantity between two units of the same length, mass,
+// temperature or volume dimension, like "10 km to miles" or "5公斤是多少磅".
+type unitMatch struct {
+	verbose bool
+}
+
+func NewUnitMatch() LogicAdapter {
+	return &unitMatch{}
+}
+
+func (match *unitMatch) CanProcess(text string) bool {
+	_, ok := convertUnits(text)
+	return ok
+}
+
+func (match *unitMatch) Process(text string, context ...string) []Answer {
+	content, ok := convertUnits(text)
+	if !ok {
+		return nil
+	}
+
+	return []Answer{{
+		Content:    content,
+		Confidence: 1,
+		Adapter:    AdapterUnit,
+	}}
+}
+
+func (match *unitMatch) SetVerbose() {
+	match.verbose = true
+}
+
+// convertUnits returns the conversion asked by the text, with the unit names
+// of the text.
+func convertUnits(text string) (string, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	var quantity, from, to string
+	if groups := unitConversion.FindStringSubmatch(text); groups != nil {
+		quantity, from, to = groups[1], groups[2], groups[3]
+	} else if groups := reverseUnitConversion.FindStringSubmatch(text); groups != nil {
+		quantity, from, to = groups[2], groups[3], groups[1]
+	} else {
+		return "", false
+	}
+
+	source, target := measureUnits[from], measureUnits[to]
+	if source.dimension != target.dimension || source == target {
+		return "", false
+	}
+
+	value, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return "", false
+	}
+
+	converted := (value*source.factor + source.offset - target.offset) / target.factor
+	return unitQuantity(quantity, from) + " = " + unitQuantity(formatNumber(converted, 4), to), true
+}
+
+// unitQuantity joins a quantity and a unit, Chinese units are not spaced.
+func unitQuantity(quantity, unit string) string {
+	if unit[0] < 0x80 {
+		return quantity + " " + unit
+	}
+	return quantity + unit
+}
@@ -0,0 +1,35 @@
+package logic
+
+import "testing"
+
+func TestUnitMatch_Process(t *testing.T) {
+	match := NewUnitMatch()
+	for question, answer := range map[string]string{
+		"10 km to miles":                "10 km = 6.2137 miles",
+		"convert 100 F to C":            "100 f = 37.7778 c",
+		"how many feet in 2 meters?":    "2 meters = 6.5617 feet",
+		"5公斤是多少磅":                       "5公斤 = 11.0231磅",
+		"3 mm in cm":                    "3 mm = 0.3 cm",
+		"what is 1.5 gallons in liters": "1.5 gallons = 5.6781 liters",
+	} {
+		if !match.CanProcess(question) {
+			t.Errorf("expected '%s' to be processed", question)
+			continue
+		}
+		answers := match.Process(question)
+		if len(answers) != 1 || answers[0].Content != answer || answers[0].Adapter != AdapterUnit {
+			t.Errorf("expected '%s' to be answered '%s', got %v", question, answer, answers)
+		}
+	}
+
+	for _, question := range []string{
+		"10 km to kg",
+		"10 km to km",
+		"I walked 10 km to the shop",
+		"miles to km",
+	} {
+		if match.CanProcess(question) {
+			t.Errorf("expected '%s' not to be processed", question)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonOutput writes the answers as {"content": "...", "confidence": 0.9} for
+// the clients expecting JSON messages.
+type jsonOutput struct{}
+
+type jsonAnswer struct {
+	Content    string  `json:"content"`
+	Confidence float32 `json:"confidence"`
+}
+
+func NewJSONOutput() OutputAdapter {
+	return jsonOutput{}
+}
+
+func (jsonOutput) Process(content string, confidence float32) (string, bool) {
+	data, err := json.Marshal(jsonAnswer{
+		Content:    content,
+		Confidence: confidence,
+	})
+	if err != nil {
+		fmt.Printf("Could not encode answer '%s': %s\n", content, err.Error())
+		return "", false
+	}
+	return string(data), true
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	markdownCode     = regexp.MustCompile("`([^`]*)`")
+	markdownStrong   = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	markdownEmphasis = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s][^*_]*?)[*_]($|[^\w*])`)
+	markdownStrike   = regexp.MustCompile(`~~(.+?)~~`)
+	markdownHeading  = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	markdownQuote    = regexp.MustCompile(`^\s{0,3}>\s?`)
+	markdownBullet   = regexp.MustCompile(`^(\s*)[*+-]\s+`)
+	markdownRule     = regexp.MustCompile(`^\s{0,3}([-*_]\s*){3,}$`)
+)
+
+// markdownOutput renders Markdown answers as plain text for the channels that
+// do not display it: links keep their URL, images their description, and
+// the emphasis, headings and code fences are removed.
+type markdownOutput struct{}
+
+func NewMarkdownOutput() OutputAdapter {
+	return markdownOutput{}
+}
+
+func (markdownOutput) Process(content string, _ float32) (string, bool) {
+	var lines []string
+	code := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			code = !code
+			continue
+		}
+		if code {
+			lines = append(lines, line)
+			continue
+		}
+		if markdownRule.MatchString(line) {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, markdownLine(line))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), true
+}
+
+func markdownLine(line string) string {
+	line = markdownHeading.ReplaceAllString(line, "")
+	line = markdownQuote.ReplaceAllString(line, "")
+	line = markdownBullet.ReplaceAllString(line, "$1- ")
+	line = markdownImage.ReplaceAllString(line, "$1")
+	line = markdownLink.ReplaceAllStringFunc(line, func(link string) string {
+		groups := markdownLink.FindStringSubmatch(link)
+		if groups[1] == groups[2] {
+			return groups[1]
+		}
+		return groups[1] + " (" + groups[2] + ")"
+	})
+	line = markdownCode.ReplaceAllString(line, "$1")
+	line = markdownStrong.ReplaceAllString(line, "$2")
+	line = markdownStrike.ReplaceAllString(line, "$1")
+	line = markdownEmphasis.ReplaceAllString(line, "$1$2$3")
+	return line
+}
@@ -1,5 +1,19 @@
 package output
 
+// OutputAdapter formats the content of an answer given with its confidence,
+// the answer is dropped when it returns false.
 type OutputAdapter interface {
 	Process(string, float32) (string, bool)
 }
+
+// Pipeline runs the adapters in turn on the content, it stops at the first
+// adapter dropping the answer.
+func Pipeline(content string, confidence float32, adapters ...OutputAdapter) (string, bool) {
+	for _, adapter := range adapters {
+		var ok bool
+		if content, ok = adapter.Process(content, confidence); !ok {
+			return "", false
+		}
+	}
+	return content, true
+}
@@ -0,0 +1,33 @@
+package output
+
+import "testing"
+
+func TestMarkdownOutput(t *testing.T) {
+	content, ok := NewMarkdownOutput().Process("# Opening hours\n\n"+
+		"We are **open** from _9am_ to `6pm`, see [our site](https://example.com).\n"+
+		"* Monday to Friday\n"+
+		"> ~~closed~~ on Sundays\n"+
+		"![map](https://example.com/map.png)\n"+
+		"```\nsnake_case *kept*\n```", 1)
+	expected := "Opening hours\n\n" +
+		"We are open from 9am to 6pm, see our site (https://example.com).\n" +
+		"- Monday to Friday\n" +
+		"closed on Sundays\n" +
+		"map\n" +
+		"snake_case *kept*"
+	if !ok || content != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	adapters := []OutputAdapter{NewMarkdownOutput(), NewTextOutput(), NewJSONOutput()}
+	content, ok := Pipeline(" **hi** ", 0.5, adapters...)
+	if !ok || content != `{"content":"hi","confidence":0.5}` {
+		t.Errorf("unexpected output %s", content)
+	}
+
+	if _, ok := Pipeline("  ", 1, adapters...); ok {
+		t.Error("expected empty answers to be dropped")
+	}
+}
@@ -0,0 +1,15 @@
+package output
+
+import "strings"
+
+// textOutput trims the answers and drops the empty ones.
+type textOutput struct{}
+
+func NewTextOutput() OutputAdapter {
+	return textOutput{}
+}
+
+func (textOutput) Process(content string, _ float32) (string, bool) {
+	content = strings.TrimSpace(content)
+	return content, content != ""
+}
@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	responsesBucket = []byte("responses")
+	indexesBucket   = []byte("indexes")
+	modelsBucket    = []byte("models")
+	// termsBucket keeps the terms each question is indexed with, so that it
+	// is removed from the same postings.
+	termsBucket = []byte("terms")
+	empty       = []byte{}
+
+	// boltOpenTimeout bounds the wait for a file locked by another storage.
+	boltOpenTimeout = 5 * time.Second
+)
+
+// boltStorage keeps responses and indexes in an embedded bolt file, nothing is
+// loaded in memory so it opens corpora bigger than RAM. Every Update is written
+// to the file, Sync flushes it to disk.
+type boltStorage struct {
+	*keywords
+	db *bolt.DB
+}
+
+func NewBoltStorage(path string) (*boltStorage, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err == bolt.ErrTimeout {
+		return nil, fmt.Errorf("could not open %s, it is locked by another process", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	db.NoSync = true
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(responsesBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(modelsBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(termsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(indexesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &boltStorage{
+		keywords: newKeywords(),
+		db:       db,
+	}, nil
+}
+
+func (storage *boltStorage) BuildIndex() {
+	var questions []string
+	storage.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(responsesBucket).ForEach(func(question, _ []byte) error {
+			questions = append(questions, string(question))
+			return nil
+		})
+	})
+	storage.updateCorpusIdf(questions, storage)
+
+	err := storage.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(indexesBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(indexesBucket); err != nil {
+			return err
+		}
+		if err := tx.DeleteBucket(termsBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(termsBucket); err != nil {
+			return err
+		}
+
+		return tx.Bucket(responsesBucket).ForEach(func(question, _ []byte) error {
+			return storage.insertIndex(tx, string(question))
+		})
+	})
+	if err != nil {
+		fmt.Printf("Could not build index: %s\n", err.Error())
+	}
+}
+
+func (storage *boltStorage) Close() error {
+	return storage.db.Close()
+}
+
+func (storage *boltStorage) Count() int {
+	var count int
+	storage.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(responsesBucket).Stats().KeyN
+		return nil
+	})
+	return count
+}
+
+func (storage *boltStorage) Find(text string, context ...string) ([]Record, bool) {
+	text = storage.Normalize(text)
+	var value []byte
+	storage.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket(responsesBucket).Get([]byte(text)); data != nil {
+			value = append([]byte(nil), data...)
+		}
+		return nil
+	})
+	if value == nil {
+		return nil, false
+	}
+
+	var records []Record
+	if err := json.Unmarshal(value, &records); err != nil {
+		fmt.Printf("Could not decode answers of '%s': %s\n", text, err.Error())
+		return nil, false
+	}
+
+	if len(context) == 1 {
+		var contextValue []Record
+		for _, record := range records {
+			if record.MatchContext(context[0]) {
+				contextValue = append(contextValue, record)
+			}
+		}
+		records = contextValue
+	}
+	return records, true
+}
+
+func (storage *boltStorage) Search(key string, context ...string) []string {
+	key = strings.ToLower(storage.Normalize(key))
+	matches := make(map[string]int)
+	storage.db.View(func(tx *bolt.Tx) error {
+		indexes := tx.Bucket(indexesBucket)
+		collector := func(word string) {
+			if postings := indexes.Bucket([]byte(word)); postings != nil {
+				postings.ForEach(func(question, _ []byte) error {
+					matches[string(question)]++
+					return nil
+				})
+			}
+		}
+
+		for _, tag := range storage.queryTags(key) {
+			collector(tag)
+		}
+		if len(matches) == 0 {
+			for _, word := range storage.queryWords(key) {
+				collector(word)
+			}
+		}
+		return nil
+	})
+
+	questions := make([]string, 0, len(matches))
+	for question := range matches {
+		questions = append(questions, question)
+	}
+	if len(questions) > maxSearchResults {
+		sort.Slice(questions, func(i, j int) bool {
+			if matches[questions[i]] != matches[questions[j]] {
+				return matches[questions[i]] > matches[questions[j]]
+			}
+			return len(questions[i]) < len(questions[j])
+		})
+		questions = questions[:maxSearchResults]
+	}
+	return questions
+}
+
+func (storage *boltStorage) LoadModel(name string) ([]byte, bool, error) {
+	var data []byte
+	err := storage.db.View(func(tx *bolt.Tx) error {
+		if value := tx.Bucket(modelsBucket).Get([]byte(name)); value != nil {
+			data = append([]byte(nil), value...)
+		}
+		return nil
+	})
+	return data, data != nil, err
+}
+
+func (storage *boltStorage) SaveModel(name string, data []byte) error {
+	return storage.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(modelsBucket).Put([]byte(name), data)
+	})
+}
+
+func (storage *boltStorage) SetIdfOptions(options IdfOptions) error {
+	return storage.setIdfOptions(options, storage)
+}
+
+func (storage *boltStorage) Persistent() bool {
+	return true
+}
+
+func (storage *boltStorage) Remove(text string) {
+	text = storage.Normalize(text)
+	err := storage.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(responsesBucket).Get([]byte(text)) == nil {
+			return nil
+		}
+		if err := tx.Bucket(responsesBucket).Delete([]byte(text)); err != nil {
+			return err
+		}
+		return storage.removeIndex(tx, text)
+	})
+	if err != nil {
+		fmt.Printf("Could not remove '%s': %s\n", text, err.Error())
+	}
+}
+
+func (storage *boltStorage) Sync() error {
+	return storage.db.Sync()
+}
+
+func (storage *boltStorage) Update(text string, responses []Record) {
+	text = storage.Normalize(text)
+	value, err := json.Marshal(responses)
+	if err != nil {
+		fmt.Printf("Could not encode answers of '%s': %s\n", text, err.Error())
+		return
+	}
+
+	err = storage.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(responsesBucket)
+		existing := bucket.Get([]byte(text)) != nil
+		if err := bucket.Put([]byte(text), value); err != nil {
+			return err
+		}
+		if existing {
+			return nil
+		}
+		return storage.insertIndex(tx, text)
+	})
+	if err != nil {
+		fmt.Printf("Could not update '%s': %s\n", text, err.Error())
+	}
+}
+
+func (storage *boltStorage) insertIndex(tx *bolt.Tx, question string) error {
+	terms := storage.terms(question)
+	value, err := json.Marshal(terms)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(termsBucket).Put([]byte(question), value); err != nil {
+		return err
+	}
+
+	indexes := tx.Bucket(indexesBucket)
+	for _, term := range terms {
+		if len(term) == 0 {
+			continue
+		}
+		postings, err := indexes.CreateBucketIfNotExists([]byte(term))
+		if err != nil {
+			return err
+		}
+		if err := postings.Put([]byte(question), empty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeIndex removes the question from the postings of the terms it was
+// indexed with.
+func (storage *boltStorage) removeIndex(tx *bolt.Tx, question string) error {
+	var terms []string
+	if err := json.Unmarshal(tx.Bucket(termsBucket).Get([]byte(question)), &terms); err != nil {
+		return err
+	}
+	if err := tx.Bucket(termsBucket).Delete([]byte(question)); err != nil {
+		return err
+	}
+
+	indexes := tx.Bucket(indexesBucket)
+	for _, term := range terms {
+		postings := indexes.Bucket([]byte(term))
+		if postings == nil {
+			continue
+		}
+		if err := postings.Delete([]byte(question)); err != nil {
+			return err
+		}
+		if first, _ := postings.Cursor().First(); first == nil {
+			if err := indexes.DeleteBucket([]byte(term)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
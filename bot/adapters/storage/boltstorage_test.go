@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestBoltStorage(t *testing.T, file string) *boltStorage {
+	storage, err := NewBoltStorage(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		storage.Close()
+	})
+	return storage
+}
+
+func TestBoltStorage(t *testing.T) {
+	testStorageAdapter(t, func(t *testing.T) StorageAdapter {
+		return newTestBoltStorage(t, filepath.Join(t.TempDir(), "corpus.bolt"))
+	})
+}
+
+func TestBoltStorage_Reopen(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "corpus.bolt")
+	storage, err := NewBoltStorage(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Update("where is the printer?", []Record{{Question: "where is the printer?", Answer: "second floor", CorpusId: 3}})
+	if err := storage.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	storage.Close()
+
+	opened, err := Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, ok := opened.(*boltStorage)
+	if !ok {
+		t.Fatalf("expected a bolt storage for %s, got %T", file, opened)
+	}
+	defer reopened.Close()
+
+	if records, ok := reopened.Find("where is the printer?"); !ok || len(records) != 1 || records[0].Answer != "second floor" {
+		t.Errorf("expected the answer to be kept, got %v", records)
+	}
+	if keys := reopened.Search("printer"); len(keys) != 1 {
+		t.Errorf("expected the index to be kept, got %v", keys)
+	}
+}
+
+func TestBoltStorage_OpenLocked(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "corpus.bolt")
+	newTestBoltStorage(t, file)
+
+	timeout := boltOpenTimeout
+	boltOpenTimeout = 50 * time.Millisecond
+	defer func() {
+		boltOpenTimeout = timeout
+	}()
+	if storage, err := NewBoltStorage(file); err == nil {
+		storage.Close()
+		t.Error("expected opening a locked file to time out")
+	}
+}
+
+func TestBoltStorage_RemoveAfterTokenizerChange(t *testing.T) {
+	storage := newTestBoltStorage(t, filepath.Join(t.TempDir(), "corpus.bolt"))
+	storage.SetTokenizer(NewWordTokenizer())
+	storage.Update("打印机坏了", []Record{{Question: "打印机坏了", Answer: "call support"}})
+	storage.BuildIndex()
+
+	storage.SetTokenizer(NewNgramTokenizer(2))
+	storage.Remove("打印机坏了")
+
+	storage.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(indexesBucket).ForEach(func(term, _ []byte) error {
+			if n := tx.Bucket(indexesBucket).Bucket(term).Stats().KeyN; n != 0 {
+				t.Errorf("expected the postings of '%s' to be removed, got %d", term, n)
+			}
+			return nil
+		})
+	})
+	storage.SetTokenizer(NewWordTokenizer())
+	if keys := storage.Search("打印机"); len(keys) != 0 {
+		t.Errorf("expected the removed question not to be found, got %v", keys)
+	}
+}
+
+func TestOpen_SqliteExtension(t *testing.T) {
+	opened, err := Open(filepath.Join(t.TempDir(), "chatbot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := opened.(*boltStorage); ok {
+		t.Error("expected a .db file not to be opened as a bolt storage, it is the sqlite DB")
+	}
+}
@@ -4,5 +4,13 @@ import "encoding/gob"
 
 type GobStorage interface {
 	StorageAdapter
+	Analyzer
+	ModelStore
+	Normalizing
+	Tokenizing
+	Expanding
+	Correcting
+	IdfComputing
+	StopWordsDetecting
 	SetOutput(*gob.Encoder)
 }
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// IdfStock weights the keywords with the idf resource only.
+	IdfStock = "stock"
+	// IdfCorpus weights the keywords with the idf computed from the keys.
+	IdfCorpus = "corpus"
+	// IdfBlend averages the idf computed from the keys with the idf resource.
+	IdfBlend = "blend"
+
+	DefaultIdfWeight = 0.5
+
+	// IdfModelName is the model the document frequencies are saved as.
+	IdfModelName = "idf"
+)
+
+type (
+	// IdfOptions selects the idf the keywords are weighted with.
+	IdfOptions struct {
+		// Mode is IdfStock, IdfCorpus or IdfBlend, IdfStock when empty.
+		Mode string
+		// Weight is the share of the corpus idf when blended,
+		// DefaultIdfWeight when it is not between 0 and 1.
+		Weight float64
+	}
+
+	// IdfComputing is implemented by the storages computing the idf of the
+	// terms of their keys when the index is built. The document frequencies
+	// are saved as the IdfModelName model, so that the storages opened again
+	// don't need to be indexed again.
+	IdfComputing interface {
+		// SetIdfOptions selects the idf, the storage must be indexed again
+		// afterwards unless the document frequencies were saved.
+		SetIdfOptions(IdfOptions) error
+		IdfReport() IdfReport
+	}
+
+	// CorpusIdf are the document frequencies of the terms of the keys.
+	CorpusIdf struct {
+		Keys      int
+		Documents map[string]int
+	}
+
+	// IdfReport tells how the terms of the keys are weighted.
+	IdfReport struct {
+		Mode  string    `json:"mode"`
+		Keys  int       `json:"keys"`
+		Terms []IdfTerm `json:"terms"`
+	}
+
+	// IdfTerm is the idf of a term of the keys, Idf is the one it is
+	// weighted with.
+	IdfTerm struct {
+		Term      string  `json:"term"`
+		Documents int     `json:"documents"`
+		Corpus    float64 `json:"corpus"`
+		Stock     float64 `json:"stock"`
+		Idf       float64 `json:"idf"`
+	}
+)
+
+// Validate checks the mode and the weight.
+func (options IdfOptions) Validate() error {
+	switch options.Mode {
+	case "", IdfStock, IdfCorpus, IdfBlend:
+	default:
+		return fmt.Errorf("unknown idf mode '%s'", options.Mode)
+	}
+	if options.Weight < 0 || options.Weight > 1 {
+		return fmt.Errorf("idf weight %v is not between 0 and 1", options.Weight)
+	}
+	return nil
+}
+
+// computed reports whether the idf is computed from the keys.
+func (options IdfOptions) computed() bool {
+	return options.Mode == IdfCorpus || options.Mode == IdfBlend
+}
+
+func (options IdfOptions) weight() float64 {
+	if options.Weight <= 0 || options.Weight > 1 {
+		return DefaultIdfWeight
+	}
+	return options.Weight
+}
+
+// combine returns the idf table of the options, the terms missing from the
+// keys keep the stock idf when blended and get the median otherwise.
+func (options IdfOptions) combine(stock *idfTable, corpus *CorpusIdf) *idfTable {
+	if !options.computed() || corpus == nil || corpus.Keys == 0 {
+		return stock
+	}
+
+	table := newIdfTable()
+	if options.Mode == IdfBlend {
+		for term, frequency := range stock.frequencies {
+			table.frequencies[term] = frequency
+		}
+	}
+	weight := options.weight()
+	for term := range corpus.Documents {
+		idf := corpus.idf(term)
+		if options.Mode == IdfBlend {
+			idf = weight*idf + (1-weight)*stock.frequency(term)
+		}
+		table.frequencies[term] = idf
+	}
+	table.updateMedian()
+	return table
+}
+
+// countDocuments returns the number of keys each token appears in.
+func countDocuments(keys []string, tokens func(string) []string) *CorpusIdf {
+	corpus := &CorpusIdf{Documents: make(map[string]int)}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		corpus.Keys++
+		seen := make(map[string]bool)
+		for _, token := range tokens(key) {
+			if !seen[token] {
+				seen[token] = true
+				corpus.Documents[token]++
+			}
+		}
+	}
+	return corpus
+}
+
+// idf is the smoothed inverse document frequency of the term, it is at least
+// 1 so that the terms of every key are still weighted.
+func (corpus *CorpusIdf) idf(term string) float64 {
+	return math.Log(float64(corpus.Keys+1)/float64(corpus.Documents[term]+1)) + 1
+}
+
+// corpusIdfData is encoded by gob in place of CorpusIdf, which is a
+// BinaryMarshaler itself.
+type corpusIdfData CorpusIdf
+
+func (corpus *CorpusIdf) MarshalBinary() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode((*corpusIdfData)(corpus)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (corpus *CorpusIdf) UnmarshalBinary(data []byte) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode((*corpusIdfData)(corpus))
+}
+
+// report returns the idf of every term of the keys, from the most common.
+func (v *vocabulary) report() IdfReport {
+	report := IdfReport{Mode: v.options.Mode, Terms: []IdfTerm{}}
+	if report.Mode == "" {
+		report.Mode = IdfStock
+	}
+	if v.corpus == nil {
+		return report
+	}
+
+	report.Keys = v.corpus.Keys
+	for term, documents := range v.corpus.Documents {
+		report.Terms = append(report.Terms, IdfTerm{
+			Term:      term,
+			Documents: documents,
+			Corpus:    v.corpus.idf(term),
+			Stock:     v.stock.frequency(term),
+			Idf:       v.idf.frequency(term),
+		})
+	}
+	sort.Slice(report.Terms, func(i, j int) bool {
+		if report.Terms[i].Idf != report.Terms[j].Idf {
+			return report.Terms[i].Idf < report.Terms[j].Idf
+		}
+		return report.Terms[i].Term < report.Terms[j].Term
+	})
+	return report
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIdfOptions_Validate(t *testing.T) {
+	for _, options := range []IdfOptions{{}, {Mode: IdfCorpus}, {Mode: IdfBlend, Weight: 0.3}} {
+		if err := options.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", options, err)
+		}
+	}
+	for _, options := range []IdfOptions{{Mode: "unknown"}, {Mode: IdfBlend, Weight: 2}} {
+		if err := options.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", options)
+		}
+	}
+}
+
+func TestMemoryStorage_CorpusIdf(t *testing.T) {
+	resources := &Resources{Idf: []byte("password 10\nprinter 4\n")}
+	newStorage := func(options IdfOptions) *memoryStorage {
+		storage := NewMemoryStorage()
+		storage.SetTokenizer(NewWordTokenizer())
+		if err := storage.SetResources(resources); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.SetIdfOptions(options); err != nil {
+			t.Fatal(err)
+		}
+		return storage
+	}
+
+	storage := newStorage(IdfOptions{Mode: IdfCorpus})
+	for _, key := range []string{"reset my password", "password expired", "printer is offline"} {
+		storage.Update(key, []Record{{Question: key, Answer: "answer"}})
+	}
+	storage.BuildIndex()
+
+	password, printer := math.Log(4.0/3)+1, math.Log(4.0/2)+1
+	if idf := storage.Idf("password"); math.Abs(idf-password) > 1e-9 {
+		t.Errorf("expected the idf of the keys for password, got %v", idf)
+	}
+	if storage.Idf("password") >= storage.Idf("printer") {
+		t.Error("expected the most common term to weigh the least")
+	}
+
+	report := storage.IdfReport()
+	if report.Mode != IdfCorpus || report.Keys != 3 || len(report.Terms) == 0 {
+		t.Fatalf("expected the report of the keys, got %+v", report)
+	}
+	if term := report.Terms[0]; term.Term != "password" || term.Documents != 2 || term.Stock != 10 {
+		t.Errorf("expected the most common term first with its stock idf, got %+v", term)
+	}
+
+	data, ok, _ := storage.LoadModel(IdfModelName)
+	if !ok {
+		t.Fatal("expected the document frequencies to be saved")
+	}
+	restored := NewMemoryStorage()
+	restored.SaveModel(IdfModelName, data)
+	restored.SetResources(resources)
+	if err := restored.SetIdfOptions(IdfOptions{Mode: IdfBlend, Weight: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if idf, want := restored.Idf("printer"), (printer+4)/2; math.Abs(idf-want) > 1e-9 {
+		t.Errorf("expected the saved idf blended with the stock one, got %v want %v", idf, want)
+	}
+
+	if idf := newStorage(IdfOptions{}).Idf("password"); idf != 10 {
+		t.Errorf("expected the stock idf by default, got %v", idf)
+	}
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
+	"github.com/tal-tech/go-zero/core/lang"
+)
+
+type (
+	// Analyzer is implemented by the storages that expose how they segment
+	// keys, so that logic adapters can score with the same tokens.
+	Analyzer interface {
+		// Tokens returns every lower-cased word of the text, in order.
+		Tokens(string) []string
+		// Idf returns the inverse document frequency of the term.
+		Idf(string) float64
+	}
+
+	// Weigher weights the words a tokenizer picks the keywords of a text
+	// from.
+	Weigher interface {
+		Idf(string) float64
+		IsStopWord(string) bool
+	}
+
+	// keywords segments keys with its tokenizer, jieba by default, it is
+	// shared by the storages so that they index and search the same terms.
+	keywords struct {
+		// vocabulary holds a *vocabulary, it is replaced as a whole so that
+		// the resources can be reloaded while the storage is queried.
+		vocabulary atomic.Value
+		// normalizer rewrites the keys before they are indexed or looked
+		// up, nil keeps them as they are.
+		normalizer nlp.Normalizer
+	}
+
+	// vocabulary is what the keys are segmented and weighted with. idf
+	// combines the stock idf of the resources with the one computed from the
+	// keys, as selected by the options.
+	vocabulary struct {
+		tokenizer Tokenizer
+		stock     *idfTable
+		corpus    *CorpusIdf
+		options   IdfOptions
+		idf       *idfTable
+		stopWords map[string]bool
+	}
+
+	// idfTable is the idf resource the keywords are weighted with, terms
+	// missing from it get the median like jieba does.
+	idfTable struct {
+		frequencies map[string]float64
+		median      float64
+	}
+)
+
+var (
+	defaultVocabulary     *vocabulary
+	defaultVocabularyOnce sync.Once
+)
+
+func newKeywords() *keywords {
+	defaultVocabularyOnce.Do(func() {
+		resources := DefaultResources()
+		stock := parseIdf(resources.Idf)
+		defaultVocabulary = &vocabulary{
+			tokenizer: NewJiebaTokenizer(),
+			stock:     stock,
+			idf:       stock,
+			stopWords: parseStopWords(resources.StopWords),
+		}
+	})
+
+	k := &keywords{}
+	k.vocabulary.Store(defaultVocabulary)
+	return k
+}
+
+func (k *keywords) current() *vocabulary {
+	return k.vocabulary.Load().(*vocabulary)
+}
+
+// SetTokenizer sets how the keys are segmented, the storage must be indexed
+// again afterwards.
+func (k *keywords) SetTokenizer(tokenizer Tokenizer) {
+	vocabulary := *k.current()
+	vocabulary.tokenizer = tokenizer
+	k.vocabulary.Store(&vocabulary)
+}
+
+// SetResources replaces the idf table and the stop words, the default ones are
+// kept for the missing resources. The dictionary is the one of the tokenizer,
+// see NewTokenizer. The storage must be indexed again afterwards.
+func (k *keywords) SetResources(resources *Resources) error {
+	if err := resources.Validate(); err != nil {
+		return err
+	}
+
+	resources = resources.withDefaults()
+	vocabulary := *k.current()
+	vocabulary.stock = parseIdf(resources.Idf)
+	vocabulary.idf = vocabulary.options.combine(vocabulary.stock, vocabulary.corpus)
+	vocabulary.stopWords = parseStopWords(resources.StopWords)
+	k.vocabulary.Store(&vocabulary)
+	return nil
+}
+
+// setIdfOptions selects the idf, the document frequencies saved in the models
+// are used until the keys are indexed again.
+func (k *keywords) setIdfOptions(options IdfOptions, models ModelStore) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	vocabulary := *k.current()
+	vocabulary.options = options
+	if options.computed() && vocabulary.corpus == nil {
+		data, ok, err := models.LoadModel(IdfModelName)
+		if err != nil {
+			return err
+		}
+		if ok {
+			corpus := &CorpusIdf{}
+			if err := corpus.UnmarshalBinary(data); err != nil {
+				return err
+			}
+			vocabulary.corpus = corpus
+		}
+	}
+	vocabulary.idf = options.combine(vocabulary.stock, vocabulary.corpus)
+	k.vocabulary.Store(&vocabulary)
+	return nil
+}
+
+// updateCorpusIdf computes the idf of the terms of the keys, before they are
+// indexed, and saves the document frequencies in the models. Nothing is
+// computed with the stock idf.
+func (k *keywords) updateCorpusIdf(keys []string, models ModelStore) {
+	if !k.current().options.computed() {
+		return
+	}
+
+	corpus := countDocuments(keys, k.Tokens)
+	vocabulary := *k.current()
+	vocabulary.corpus = corpus
+	vocabulary.idf = vocabulary.options.combine(vocabulary.stock, corpus)
+	k.vocabulary.Store(&vocabulary)
+
+	data, err := corpus.MarshalBinary()
+	if err == nil {
+		err = models.SaveModel(IdfModelName, data)
+	}
+	if err != nil {
+		fmt.Printf("Could not save the idf of the keys: %s\n", err.Error())
+	}
+}
+
+// IdfReport tells how the terms of the keys are weighted, the terms are only
+// known once the idf is computed from the keys.
+func (k *keywords) IdfReport() IdfReport {
+	return k.current().report()
+}
+
+// SetNormalizer sets how the keys are normalized, it must be called before
+// the storage is trained or queried.
+func (k *keywords) SetNormalizer(normalizer nlp.Normalizer) {
+	k.normalizer = normalizer
+}
+
+// Normalize returns the text as it is indexed and looked up.
+func (k *keywords) Normalize(text string) string {
+	if k.normalizer == nil {
+		return text
+	}
+	return k.normalizer(text)
+}
+
+func (k *keywords) Tokens(text string) []string {
+	var tokens []string
+	for _, word := range k.current().tokenizer.Cut(strings.ToLower(k.Normalize(text))) {
+		word = strings.TrimSpace(word)
+		if isWord(word) {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+func (k *keywords) Idf(term string) float64 {
+	return k.current().Idf(term)
+}
+
+func (v *vocabulary) Idf(term string) float64 {
+	return v.idf.frequency(term)
+}
+
+// IsStopWord reports whether the lower-cased word is a stop word.
+func (v *vocabulary) IsStopWord(word string) bool {
+	return v.stopWords[strings.ToLower(word)]
+}
+
+// terms returns the lower-cased index terms of the key, without duplicates.
+func (k *keywords) terms(key string) []string {
+	var terms []string
+	seen := make(map[string]lang.PlaceholderType)
+	collector := func(word string) {
+		word = strings.ToLower(word)
+		if _, ok := seen[word]; !ok {
+			seen[word] = lang.Placeholder
+			terms = append(terms, word)
+		}
+	}
+
+	vocabulary := k.current()
+	if len([]rune(key)) > thresholdForKeywords {
+		for _, tag := range vocabulary.tokenizer.Keywords(key, topKeywords, vocabulary) {
+			collector(tag)
+		}
+	} else {
+		for _, word := range vocabulary.tokenizer.Cut(key) {
+			collector(word)
+		}
+	}
+
+	return terms
+}
+
+// queryTags returns the keywords to look up for a lower-cased query.
+func (k *keywords) queryTags(query string) []string {
+	if len([]rune(query)) > thresholdForKeywords {
+		vocabulary := k.current()
+		return vocabulary.tokenizer.Keywords(query, topKeywords, vocabulary)
+	}
+	return nil
+}
+
+// queryWords returns every word of a lower-cased query, it is the fallback
+// when none of the keywords are indexed.
+func (k *keywords) queryWords(query string) []string {
+	return k.current().tokenizer.Cut(query)
+}
+
+// known reports whether the word is in the dictionary of the tokenizer, so
+// that it is not a typo.
+func (k *keywords) known(word string) bool {
+	return k.current().tokenizer.Known(word)
+}
+
+func newIdfTable() *idfTable {
+	return &idfTable{
+		frequencies: make(map[string]float64),
+		median:      1,
+	}
+}
+
+func (table *idfTable) frequency(term string) float64 {
+	if frequency, ok := table.frequencies[term]; ok {
+		return frequency
+	}
+	return table.median
+}
+
+func (table *idfTable) updateMedian() {
+	if len(table.frequencies) == 0 {
+		return
+	}
+
+	frequencies := make([]float64, 0, len(table.frequencies))
+	for _, frequency := range table.frequencies {
+		frequencies = append(frequencies, frequency)
+	}
+	sort.Float64s(frequencies)
+	table.median = frequencies[len(frequencies)/2]
+}
+
+// isWord reports whether the token has any letter or digit.
+func isWord(token string) bool {
+	for _, r := range token {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
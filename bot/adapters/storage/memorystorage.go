@@ -5,24 +5,26 @@ import (
 	"fmt"
 	"github.com/tal-tech/go-zero/core/lang"
 	"github.com/tal-tech/go-zero/core/mr"
-	"github.com/wangbin/jiebago"
-	"github.com/wangbin/jiebago/analyse"
 	"math"
-	"os"
-	"sort"
 	"strings"
+	"sync"
 )
 
 const (
-	chunkSize              = 10000
-	topKeywords            = 5
-	thresholdForKeywords   = 1
-	maxSearchResults       = 100
-	thresholdForStopWords  = 100
-	dictFile               = "dict.txt"
-	idfFile                = "idf.txt"
-	stopWordsFile          = "stop_words.txt"
-	generatedStopWordsFile = "stopwords.txt"
+	chunkSize            = 10000
+	topKeywords          = 5
+	thresholdForKeywords = 1
+	maxSearchResults     = 100
+	dictFile             = "dict.txt"
+	idfFile              = "idf.txt"
+	stopWordsFile        = "stop_words.txt"
+	// terms of more than 1/thresholdForStopWords of the keys are stop words,
+	// once there are minKeysForStopWords keys
+	thresholdForStopWords = 5
+	minKeysForStopWords   = 100
+	// compact the keys once more than 1/compactRatio of the slots are removed
+	compactRatio    = 4
+	minCompactSlots = 1000
 )
 
 type (
@@ -31,24 +33,69 @@ type (
 		keys    []string
 	}
 
+	// memoryStorage is safe for concurrent use, queries hold the read lock so
+	// they always see keys, responses and indexes from the same edit.
 	memoryStorage struct {
+		*keywords
+		mu        sync.RWMutex
 		writer    *gob.Encoder
-		segmenter *jiebago.Segmenter
-		extracter *analyse.TagExtracter
 		keys      []string
-		responses map[string]map[string]int
+		responses map[string][]Record
 		indexes   map[string][]int
+		// slots maps each indexed key to its position in keys, removed keys
+		// leave an empty slot until the keys are compacted.
+		slots       map[string]int
+		removed     int
+		incremental bool
+		models      map[string][]byte
+		// synonyms expand the queries, and the keys when indexSynonyms is set.
+		synonyms      *Synonyms
+		indexSynonyms bool
+		// stopWords are detected when the index is built or restored.
+		stopWords stopWords
 	}
 )
 
 func RestoreMemoryStorage(decoder *gob.Decoder) (*memoryStorage, error) {
-	var segmenter jiebago.Segmenter
-	segmenter.LoadDictionary(dictFile)
-	var extracter analyse.TagExtracter
-	extracter.LoadDictionary(dictFile)
-	extracter.LoadIdf(idfFile)
-	extracter.LoadStopWords(stopWordsFile)
+	var header storeHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, err
+	}
+
+	if header.Version < 2 || header.Version > storeVersion {
+		return nil, fmt.Errorf("unsupported storage version %d", header.Version)
+	}
 
+	var keys []string
+	responses := make(map[string][]Record)
+	indexes := make(map[string][]int)
+
+	if err := decoder.Decode(&keys); err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(&responses); err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(&indexes); err != nil {
+		return nil, err
+	}
+
+	storage := NewMemoryStorage()
+	if header.Version >= modelsVersion {
+		if err := decoder.Decode(&storage.models); err != nil {
+			return nil, err
+		}
+	}
+	storage.restoreIndex(keys, indexes)
+	storage.responses = responses
+	return storage, nil
+}
+
+// RestoreLegacyMemoryStorage reads a storage written before answers became
+// records, and migrates the "$$$$"-joined answers.
+func RestoreLegacyMemoryStorage(decoder *gob.Decoder) (*memoryStorage, error) {
 	var keys []string
 	responses := make(map[string]map[string]int)
 	indexes := make(map[string][]int)
@@ -65,73 +112,155 @@ func RestoreMemoryStorage(decoder *gob.Decoder) (*memoryStorage, error) {
 		return nil, err
 	}
 
-	return &memoryStorage{
-		segmenter: &segmenter,
-		extracter: &extracter,
-		keys:      keys,
-		responses: responses,
-		indexes:   indexes,
-	}, nil
+	storage := NewMemoryStorage()
+	storage.restoreIndex(keys, indexes)
+	storage.responses = migrateLegacyResponses(responses)
+	return storage, nil
 }
 
 func NewMemoryStorage() *memoryStorage {
-	var segmenter jiebago.Segmenter
-	segmenter.LoadDictionary(dictFile)
-	var extracter analyse.TagExtracter
-	extracter.LoadDictionary(dictFile)
-	extracter.LoadIdf(idfFile)
-	extracter.LoadStopWords(stopWordsFile)
-
 	return &memoryStorage{
-		segmenter: &segmenter,
-		extracter: &extracter,
-		responses: make(map[string]map[string]int),
+		keywords:  newKeywords(),
+		responses: make(map[string][]Record),
 		indexes:   make(map[string][]int),
+		slots:     make(map[string]int),
+		models:    make(map[string][]byte),
 	}
 }
 
+// BuildIndex re-segments every key, once built the index is kept up to date
+// by Update and Remove. Queries keep using the previous index while the new
+// one is built, edits made in the meantime are applied before swapping.
 func (storage *memoryStorage) BuildIndex() {
-	storage.keys = storage.buildKeys()
-	storage.indexes = storage.buildIndex(storage.keys)
-	storage.saveStopWords()
+	storage.mu.RLock()
+	keys := storage.buildKeys()
+	synonyms := storage.indexedSynonyms()
+	storage.mu.RUnlock()
+
+	storage.updateCorpusIdf(keys, storage)
+	slots := buildSlots(keys)
+	indexes := storage.buildIndex(keys, synonyms)
+	if indexes == nil {
+		indexes = make(map[string][]int)
+	}
+	detected := detectStopWords(indexes, len(keys))
+
+	storage.mu.Lock()
+	storage.stopWords.detected = detected
+	storage.keys = keys
+	storage.slots = slots
+	storage.indexes = indexes
+	storage.removed = 0
+	storage.incremental = true
+	for key := range storage.responses {
+		storage.insertKey(key)
+	}
+	for key := range slots {
+		if _, ok := storage.responses[key]; !ok {
+			storage.removeKey(key)
+		}
+	}
+	storage.mu.Unlock()
 }
 
 func (storage *memoryStorage) Count() int {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
 	return len(storage.responses)
 }
 
-func (storage *memoryStorage) Find(text string, context ...string) (map[string]int, bool) {
+// Find returns a copy of the records, callers are free to modify it.
+func (storage *memoryStorage) Find(text string, context ...string) ([]Record, bool) {
+	text = storage.Normalize(text)
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+
 	value, ok := storage.responses[text]
 	if ok && len(context) == 1 {
-		contextValue := map[string]int{}
-		for answer, v := range value {
-			a := strings.Split(answer, "$$$$")
-			if len(a) == 4 {
-				if a[3] == context[0] {
-					contextValue[answer] = v
-				}
-			} else {
-				contextValue[answer] = v
+		var contextValue []Record
+		for _, record := range value {
+			if record.MatchContext(context[0]) {
+				contextValue = append(contextValue, record)
 			}
 		}
 		value = contextValue
+	} else if ok {
+		value = append([]Record(nil), value...)
 	}
 	return value, ok
 }
 
-func (storage *memoryStorage) FindWithContext(text, context string) (map[string]int, bool) {
-	value, ok := storage.responses[text]
-	return value, ok
+func (storage *memoryStorage) Search(key string, context ...string) []string {
+	key = strings.ToLower(storage.Normalize(key))
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+
+	keys, _ := storage.search(key)
+	return keys
 }
 
-func (storage *memoryStorage) Search(key string, context ...string) []string {
-	key = strings.ToLower(key)
+func (storage *memoryStorage) SearchExpanded(key string, context ...string) ([]string, map[string][]Expansion) {
+	key = strings.ToLower(storage.Normalize(key))
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+
+	keys, expansions := storage.search(key)
+	if len(expansions) == 0 {
+		return keys, nil
+	}
+
+	matches := make(map[string][]Expansion)
+	for _, each := range keys {
+		if found := matched(expansions, strings.ToLower(each)); len(found) > 0 {
+			matches[each] = found
+		}
+	}
+	return keys, matches
+}
+
+// Correct replaces the words of the query missing from the index with the
+// closest indexed terms.
+func (storage *memoryStorage) Correct(query string) (string, bool) {
+	query = strings.ToLower(storage.Normalize(query))
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+
+	return correctWords(query, func(word string) bool {
+		_, ok := storage.indexes[word]
+		return ok || storage.known(word)
+	}, storage.indexes)
+}
+
+// SetSynonyms normalizes the synonyms like the keys, BuildIndex must be called
+// when they are indexed.
+func (storage *memoryStorage) SetSynonyms(synonyms *Synonyms, indexed bool) {
+	synonyms = synonyms.normalized(func(term string) string {
+		return strings.ToLower(storage.Normalize(term))
+	})
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.synonyms = synonyms
+	storage.indexSynonyms = indexed
+}
+
+// search looks up the lower-cased key and the synonyms of its terms, the read
+// lock must be held.
+func (storage *memoryStorage) search(key string) ([]string, []Expansion) {
+	expansions := storage.synonyms.expansions(key)
+
 	ids := make(map[int]int8)
 	var maxMatches int8
 	collector := func(word string) {
 		if wordIds, ok := storage.indexes[word]; ok {
 			for _, id := range wordIds {
+				if len(storage.keys[id]) == 0 {
+					continue
+				}
+				// synonyms may collect more terms than the counters hold
 				current := ids[id]
+				if current == math.MaxInt8 {
+					continue
+				}
 				ids[id] = current + 1
 				if current+1 > maxMatches {
 					maxMatches = current + 1
@@ -140,35 +269,74 @@ func (storage *memoryStorage) Search(key string, context ...string) []string {
 		}
 	}
 
-	if len([]rune(key)) > thresholdForKeywords {
-		tags := storage.extracter.ExtractTags(key, topKeywords)
-		for i := range tags {
-			collector(tags[i].Text())
+	// the stop words are only looked up when the other words match no key,
+	// they match too many keys to tell them apart
+	lookup := func(words []string) {
+		var stopWords []string
+		for _, word := range words {
+			if storage.stopWords.contains(word) {
+				stopWords = append(stopWords, word)
+			} else {
+				collector(word)
+			}
 		}
+		if len(ids) == 0 {
+			for _, word := range stopWords {
+				collector(word)
+			}
+		}
+	}
+
+	tags := storage.queryTags(key)
+	for _, expansion := range expansions {
+		tags = append(tags, storage.queryTags(expansion.Synonym)...)
 	}
+	lookup(tags)
 
 	if len(ids) == 0 {
-		for word := range storage.segmenter.Cut(key, true) {
-			collector(word)
+		words := storage.queryWords(key)
+		for _, expansion := range expansions {
+			words = append(words, storage.queryWords(expansion.Synonym)...)
 		}
+		lookup(words)
 	}
 
 	if len(ids) > maxSearchResults {
-		return storage.generateFromMoreMatches(ids, maxMatches)
+		return storage.generateFromMoreMatches(ids, maxMatches), expansions
 	} else {
-		return storage.generateFromLessMatches(ids)
+		return storage.generateFromLessMatches(ids), expansions
 	}
 }
 
 func (storage *memoryStorage) Remove(text string) {
+	text = storage.Normalize(text)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
 	delete(storage.responses, text)
+	if storage.incremental {
+		storage.removeKey(text)
+	}
 }
 
 func (storage *memoryStorage) SetOutput(output *gob.Encoder) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
 	storage.writer = output
 }
 
 func (storage *memoryStorage) Sync() error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	if storage.removed > 0 {
+		storage.compact()
+	}
+
+	if err := storage.writer.Encode(storeHeader{Version: storeVersion}); err != nil {
+		return err
+	}
+
 	if err := storage.writer.Encode(storage.keys); err != nil {
 		return err
 	}
@@ -177,28 +345,59 @@ func (storage *memoryStorage) Sync() error {
 		return err
 	}
 
-	return storage.writer.Encode(storage.indexes)
+	if err := storage.writer.Encode(storage.indexes); err != nil {
+		return err
+	}
+
+	return storage.writer.Encode(storage.models)
 }
 
-//TODO 大量处理字符串，性能不是很好，后期考虑优化
-func (storage *memoryStorage) Update(text string, responses map[string]int) {
+// SaveModel keeps the model in memory, it is written by Sync.
+func (storage *memoryStorage) SaveModel(name string, data []byte) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.models[name] = data
+	return nil
+}
+
+func (storage *memoryStorage) LoadModel(name string) ([]byte, bool, error) {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	data, ok := storage.models[name]
+	return data, ok, nil
+}
+
+func (storage *memoryStorage) StopWords() StopWords {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	return storage.stopWords.report()
+}
+
+// SetStopWordOverrides normalizes the terms like the keys.
+func (storage *memoryStorage) SetStopWordOverrides(included, excluded []string) {
+	normalize := func(term string) string {
+		return storage.Normalize(term)
+	}
+	includedSet, excludedSet := termSet(included, normalize), termSet(excluded, normalize)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.stopWords.included = includedSet
+	storage.stopWords.excluded = excludedSet
+}
+
+func (storage *memoryStorage) SetIdfOptions(options IdfOptions) error {
+	return storage.setIdfOptions(options, storage)
+}
+
+func (storage *memoryStorage) Update(text string, responses []Record) {
+	text = storage.Normalize(text)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
 	storage.responses[text] = responses
-	//titles := strings.Split(text, "|")
-	//if len(titles) == 1 {
-	//	titles = strings.Split(text, "｜")
-	//}
-	//for _, title := range titles {
-	//	for k, _ := range responses {
-	//		kv := strings.Split(k, "$$$$")
-	//		if len(kv)>1 {
-	//			response := make(map[string]int)
-	//			response[fmt.Sprintf("%s$$$$%s", title, kv[1])] = 1
-	//			storage.responses[title] = response
-	//		} else {
-	//			storage.responses[title] = responses
-	//		}
-	//	}
-	//}
+	if storage.incremental {
+		storage.insertKey(text)
+	}
 }
 
 func (storage *memoryStorage) buildKeys() []string {
@@ -213,7 +412,39 @@ func (storage *memoryStorage) buildKeys() []string {
 	return keys
 }
 
-func (storage *memoryStorage) buildIndex(keys []string) map[string][]int {
+// indexedSynonyms returns the synonyms the keys are indexed with, nil when
+// they only expand the queries.
+func (storage *memoryStorage) indexedSynonyms() *Synonyms {
+	if !storage.indexSynonyms {
+		return nil
+	}
+	return storage.synonyms
+}
+
+// keyTerms returns the index terms of the key and of the synonyms it has.
+func (storage *memoryStorage) keyTerms(key string, synonyms *Synonyms) []string {
+	terms := storage.terms(key)
+	expansions := synonyms.expansions(strings.ToLower(key))
+	if len(expansions) == 0 {
+		return terms
+	}
+
+	seen := make(map[string]lang.PlaceholderType, len(terms))
+	for _, term := range terms {
+		seen[term] = lang.Placeholder
+	}
+	for _, expansion := range expansions {
+		for _, term := range storage.terms(expansion.Synonym) {
+			if _, ok := seen[term]; !ok {
+				seen[term] = lang.Placeholder
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+func (storage *memoryStorage) buildIndex(keys []string, synonyms *Synonyms) map[string][]int {
 	channel := make(chan interface{})
 
 	go func() {
@@ -229,7 +460,7 @@ func (storage *memoryStorage) buildIndex(keys []string) map[string][]int {
 		for i := range chunks {
 			source <- chunks[i]
 		}
-	}, storage.mapper, storage.reducer)
+	}, storage.mapper(synonyms), storage.reducer)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
 		return nil
@@ -238,34 +469,85 @@ func (storage *memoryStorage) buildIndex(keys []string) map[string][]int {
 	return result.(map[string][]int)
 }
 
-func (storage *memoryStorage) saveStopWords() {
-	f, err := os.Create(generatedStopWordsFile)
-	if err != nil {
+func (storage *memoryStorage) restoreIndex(keys []string, indexes map[string][]int) {
+	storage.keys = keys
+	storage.slots = buildSlots(keys)
+	storage.indexes = indexes
+	storage.removed = len(keys) - len(storage.slots)
+	storage.incremental = true
+	storage.stopWords.detected = detectStopWords(indexes, len(storage.slots))
+}
+
+func (storage *memoryStorage) insertKey(key string) {
+	if _, ok := storage.slots[key]; ok {
+		return
+	}
+
+	slot := len(storage.keys)
+	storage.keys = append(storage.keys, key)
+	storage.slots[key] = slot
+	for _, term := range storage.keyTerms(key, storage.indexedSynonyms()) {
+		storage.indexes[term] = append(storage.indexes[term], slot)
+	}
+}
+
+// removeKey only empties the slot of the key, the dead ids are skipped by
+// Search and dropped from the posting lists when the keys are compacted.
+func (storage *memoryStorage) removeKey(key string) {
+	slot, ok := storage.slots[key]
+	if !ok {
 		return
 	}
-	defer f.Close()
 
-	stopWords := make(map[int][]string)
-	for key, value := range storage.indexes {
-		stopWords[len(value)] = append(stopWords[len(value)], key)
+	delete(storage.slots, key)
+	storage.keys[slot] = ""
+	storage.removed++
+	if storage.removed >= minCompactSlots && storage.removed*compactRatio > len(storage.keys) {
+		storage.compact()
 	}
+}
 
-	var mostOccurrences sort.IntSlice
-	for key := range stopWords {
-		if key > len(storage.keys)/thresholdForStopWords {
-			mostOccurrences = append(mostOccurrences, key)
+// compact drops the empty slots from keys and renumbers the posting lists,
+// without segmenting the keys again.
+func (storage *memoryStorage) compact() {
+	keys := make([]string, 0, len(storage.slots))
+	mapping := make([]int, len(storage.keys))
+	for slot, key := range storage.keys {
+		if current, ok := storage.slots[key]; ok && current == slot {
+			mapping[slot] = len(keys)
+			storage.slots[key] = len(keys)
+			keys = append(keys, key)
+		} else {
+			mapping[slot] = -1
 		}
 	}
 
-	mostOccurrences.Sort()
-	for i := range mostOccurrences {
-		occurrence := mostOccurrences[len(mostOccurrences)-(i+1)]
-		fmt.Fprintf(f, "%d:\n", occurrence)
-		ids := stopWords[occurrence]
-		for i := range ids {
-			fmt.Fprintf(f, "\t%s\n", ids[i])
+	for term, ids := range storage.indexes {
+		alive := ids[:0]
+		for _, id := range ids {
+			if mapping[id] >= 0 {
+				alive = append(alive, mapping[id])
+			}
+		}
+		if len(alive) == 0 {
+			delete(storage.indexes, term)
+		} else {
+			storage.indexes[term] = alive
 		}
 	}
+
+	storage.keys = keys
+	storage.removed = 0
+}
+
+func buildSlots(keys []string) map[string]int {
+	slots := make(map[string]int, len(keys))
+	for slot, key := range keys {
+		if len(key) > 0 {
+			slots[key] = slot
+		}
+	}
+	return slots
 }
 
 func (storage *memoryStorage) findShortestStrings(indexes []int, total int) []int {
@@ -337,36 +619,19 @@ func (storage *memoryStorage) generateSearchResults(ids []int) []string {
 	return result
 }
 
-func (storage *memoryStorage) mapper(data interface{}, writer mr.Writer, cancel func(error)) {
-	indexes := make(map[string][]int)
-	chunk := data.(*keyChunk)
-
-	for i := range chunk.keys {
-		collector := func(word string) {
-			if ids, ok := indexes[word]; ok {
-				// ids is never empty
-				if ids[len(ids)-1] != chunk.offfset+i {
-					indexes[word] = append(ids, chunk.offfset+i)
-				}
-			} else {
-				indexes[word] = []int{chunk.offfset + i}
-			}
-		}
+func (storage *memoryStorage) mapper(synonyms *Synonyms) mr.MapperFunc {
+	return func(data interface{}, writer mr.Writer, cancel func(error)) {
+		indexes := make(map[string][]int)
+		chunk := data.(*keyChunk)
 
-		key := chunk.keys[i]
-		if len([]rune(key)) > thresholdForKeywords {
-			tags := storage.extracter.ExtractTags(key, topKeywords)
-			for i := range tags {
-				collector(strings.ToLower(tags[i].Text()))
-			}
-		} else {
-			for word := range storage.segmenter.Cut(key, true) {
-				collector(strings.ToLower(word))
+		for i := range chunk.keys {
+			for _, term := range storage.keyTerms(chunk.keys[i], synonyms) {
+				indexes[term] = append(indexes[term], chunk.offfset+i)
 			}
 		}
-	}
 
-	writer.Write(indexes)
+		writer.Write(indexes)
+	}
 }
 
 func (storage *memoryStorage) reducer(input <-chan interface{}, writer mr.Writer, cancel func(error)) {
@@ -421,6 +686,9 @@ func splitStrings(slice []string, size int) []*keyChunk {
 }
 
 func (storage *memoryStorage) print() {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+
 	fmt.Println("Questions:")
 	for _, key := range storage.keys {
 		fmt.Printf("\t%s\n", key)
@@ -429,8 +697,8 @@ func (storage *memoryStorage) print() {
 	fmt.Println("Responses:")
 	for key, value := range storage.responses {
 		fmt.Printf("\t%s:\n", key)
-		for text, occurrence := range value {
-			fmt.Printf("\t\t%s:\t%d\n", text, occurrence)
+		for _, record := range value {
+			fmt.Printf("\t\t%s:\t%d\n", record.Answer, record.Occurrence)
 		}
 	}
 
@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
+)
+
+// TestMain runs the tests in a scratch directory, so that no default resource
+// of the working directory is loaded.
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newIndexedStorage(size int) *memoryStorage {
+	storage := NewMemoryStorage()
+	for i := 0; i < size; i++ {
+		key := fmt.Sprintf("how do I reset device%d for team%d", i, i%100)
+		storage.Update(key, []Record{{Question: key, Answer: "answer", Occurrence: 1}})
+	}
+	storage.BuildIndex()
+	return storage
+}
+
+func contains(keys []string, key string) bool {
+	for _, each := range keys {
+		if each == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMemoryStorage_IncrementalUpdate(t *testing.T) {
+	storage := newIndexedStorage(10)
+
+	key := "where is the printer driver"
+	storage.Update(key, []Record{{Question: key, Answer: "on the share"}})
+	if keys := storage.Search("printer driver"); !contains(keys, key) {
+		t.Errorf("expected '%s' to be searchable after update, got %v", key, keys)
+	}
+
+	// updating the answers of an indexed key must not index it twice
+	storage.Update(key, []Record{{Question: key, Answer: "on the wiki"}})
+	if ids := storage.indexes["printer"]; len(ids) != 1 {
+		t.Errorf("expected one posting for 'printer', got %v", ids)
+	}
+
+	storage.Remove(key)
+	if keys := storage.Search("printer driver"); contains(keys, key) {
+		t.Errorf("expected '%s' to be gone after remove, got %v", key, keys)
+	}
+
+	storage.Update(key, []Record{{Question: key, Answer: "on the share"}})
+	if keys := storage.Search("printer driver"); len(keys) != 1 || keys[0] != key {
+		t.Errorf("expected '%s' to be searchable after re-adding, got %v", key, keys)
+	}
+}
+
+func TestMemoryStorage_Compact(t *testing.T) {
+	storage := newIndexedStorage(2 * minCompactSlots)
+
+	for i := 0; i < minCompactSlots; i++ {
+		storage.Remove(fmt.Sprintf("how do I reset device%d for team%d", i, i%100))
+	}
+
+	if storage.removed != 0 {
+		t.Fatalf("expected the keys to be compacted, %d slots are still removed", storage.removed)
+	}
+	if len(storage.keys) != minCompactSlots {
+		t.Errorf("expected %d keys after compaction, got %d", minCompactSlots, len(storage.keys))
+	}
+	for key, slot := range storage.slots {
+		if storage.keys[slot] != key {
+			t.Fatalf("slot %d of '%s' holds '%s'", slot, key, storage.keys[slot])
+		}
+	}
+
+	removed := "how do I reset device1 for team1"
+	kept := fmt.Sprintf("how do I reset device%d for team%d", minCompactSlots+1, (minCompactSlots+1)%100)
+	keys := storage.Search("reset device1 team1")
+	if contains(keys, removed) {
+		t.Errorf("expected '%s' to be removed, got %v", removed, keys)
+	}
+	keys = storage.Search(fmt.Sprintf("reset device%d", minCompactSlots+1))
+	if !contains(keys, kept) {
+		t.Errorf("expected '%s' to be searchable after compaction, got %v", kept, keys)
+	}
+}
+
+// BenchmarkMemoryStorage_SingleEdit measures adding and removing one key on an
+// indexed storage, the latency should not grow with the number of keys.
+func BenchmarkMemoryStorage_SingleEdit(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("keys-%d", size), func(b *testing.B) {
+			storage := newIndexedStorage(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := fmt.Sprintf("where is the printer%d driver", i)
+				storage.Update(key, []Record{{Question: key, Answer: "on the share"}})
+				storage.Remove(key)
+			}
+		})
+	}
+}
+
+// BenchmarkMemoryStorage_BuildIndex is the cost of an edit before the index
+// was maintained incrementally.
+func BenchmarkMemoryStorage_BuildIndex(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("keys-%d", size), func(b *testing.B) {
+			storage := newIndexedStorage(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				storage.BuildIndex()
+			}
+		})
+	}
+}
+
+func TestMemoryStorage_ConcurrentAccess(t *testing.T) {
+	storage := newIndexedStorage(100)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("where is printer%d on floor%d", i, w)
+				storage.Update(key, []Record{{Question: key, Answer: "on the share", Occurrence: 1}})
+				if i%3 == 0 {
+					storage.Remove(key)
+				}
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				for _, key := range storage.Search("printer floor") {
+					if records, ok := storage.Find(key); ok && len(records) > 0 {
+						// callers own the returned records
+						records[0].Occurrence++
+					}
+				}
+				storage.Count()
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 3; i++ {
+			storage.BuildIndex()
+		}
+	}()
+	wg.Wait()
+
+	for w := 0; w < 4; w++ {
+		for i := 0; i < 200; i++ {
+			key := fmt.Sprintf("where is printer%d on floor%d", i, w)
+			_, indexed := storage.slots[key]
+			records, found := storage.Find(key)
+			if found != (i%3 != 0) || indexed != found {
+				t.Fatalf("'%s' found: %v, indexed: %v", key, found, indexed)
+			}
+			if found && records[0].Occurrence != 1 {
+				t.Fatalf("records of '%s' were modified through Find: %v", key, records)
+			}
+		}
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorageAdapter(t, func(t *testing.T) StorageAdapter {
+		return NewMemoryStorage()
+	})
+}
+
+func TestMemoryStorage_Normalizer(t *testing.T) {
+	normalizer, err := nlp.NewNormalizer([]string{nlp.NormalizeWidth, nlp.NormalizeSimplified, nlp.NormalizeLower})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := NewMemoryStorage()
+	storage.SetNormalizer(normalizer)
+	storage.Update("如何重置设备", []Record{{Question: "如何重置设备", Answer: "长按电源键"}})
+	storage.Update("Reset WiFi", []Record{{Question: "Reset WiFi", Answer: "unplug the router"}})
+	storage.BuildIndex()
+
+	if _, ok := storage.Find("如何重置設備"); !ok {
+		t.Error("expected the traditional question to find the simplified one")
+	}
+	if _, ok := storage.Find("ＲＥＳＥＴ　ＷＩＦＩ"); !ok {
+		t.Error("expected the full-width question to find the indexed one")
+	}
+	if keys := storage.Search("wifi"); len(keys) != 1 || keys[0] != "reset wifi" {
+		t.Errorf("expected the normalized key to be searchable, got %v", keys)
+	}
+}
+
+func TestMemoryStorage_Synonyms(t *testing.T) {
+	synonyms := NewSynonyms([][]string{{"登录", "登陆", "sign in"}})
+	key := "how do I sign in to the portal"
+
+	for _, indexed := range []bool{false, true} {
+		storage := NewMemoryStorage()
+		storage.Update(key, []Record{{Question: key, Answer: "use your badge"}})
+		storage.SetSynonyms(synonyms, indexed)
+		storage.BuildIndex()
+
+		keys, expansions := storage.SearchExpanded("怎么登录")
+		if !contains(keys, key) {
+			t.Errorf("indexed=%v: expected '%s' to be found through its synonym, got %v", indexed, key, keys)
+		}
+		if want := []Expansion{{Term: "登录", Synonym: "sign in"}}; !reflect.DeepEqual(expansions[key], want) {
+			t.Errorf("indexed=%v: expected the expansions %v, got %v", indexed, want, expansions[key])
+		}
+
+		if _, expansions := storage.SearchExpanded("sign in portal"); len(expansions) != 0 {
+			t.Errorf("indexed=%v: expected no expansion for the words of the key, got %v", indexed, expansions)
+		}
+	}
+}
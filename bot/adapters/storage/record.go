@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/gob"
+	"strconv"
+	"strings"
+)
+
+const (
+	// storeVersion is written at the head of every memoryStorage in a .gob file,
+	// files without it are the legacy "$$$$"-joined format. Version 3 added
+	// the models after the indexes.
+	storeVersion        = 3
+	modelsVersion       = 3
+	legacyAnswerSep     = "$$$$"
+	legacyAnswerFields  = 3
+	legacyContextFields = 4
+)
+
+type (
+	// Record is a single answer stored for a question.
+	Record struct {
+		Question   string                 `json:"question"`
+		Answer     string                 `json:"answer"`
+		CorpusId   int                    `json:"corpus_id"`
+		Context    string                 `json:"context"`
+		Contextual bool                   `json:"contextual"`
+		State      string                 `json:"state"`
+		Class      string                 `json:"class"`
+		Data       map[string]interface{} `json:"data"`
+		Occurrence int                    `json:"occurrence"`
+	}
+
+	storeHeader struct {
+		Version int
+	}
+)
+
+func init() {
+	// Record.Data holds decoded json values
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// Same reports whether both records hold the same answer for the same corpus.
+func (record Record) Same(other Record) bool {
+	return record.CorpusId == other.CorpusId && record.Answer == other.Answer
+}
+
+// MatchContext reports whether the record can be answered in the given context.
+// Records that are not backed by a corpus always match, records of a dialogue
+// state match in that state and the others when their context is the same.
+func (record Record) MatchContext(context string) bool {
+	if record.CorpusId == 0 {
+		return true
+	}
+	if record.State != "" {
+		return record.State == context
+	}
+	return record.Context == context
+}
+
+func migrateLegacyResponses(legacy map[string]map[string]int) map[string][]Record {
+	responses := make(map[string][]Record, len(legacy))
+	for question, answers := range legacy {
+		records := make([]Record, 0, len(answers))
+		for answer, occurrence := range answers {
+			records = append(records, migrateLegacyAnswer(question, answer, occurrence))
+		}
+		responses[question] = records
+	}
+	return responses
+}
+
+func migrateLegacyAnswer(question, answer string, occurrence int) Record {
+	record := Record{
+		Question:   question,
+		Answer:     answer,
+		Occurrence: occurrence,
+	}
+
+	fields := strings.Split(answer, legacyAnswerSep)
+	if len(fields) < legacyAnswerFields {
+		return record
+	}
+
+	id, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return record
+	}
+
+	record.Question = fields[0]
+	record.Answer = fields[1]
+	record.CorpusId = id
+	if len(fields) >= legacyContextFields {
+		record.Context = fields[3]
+	}
+	return record
+}
@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	ResourceDict      = "dict"
+	ResourceIdf       = "idf"
+	ResourceStopWords = "stop_words"
+)
+
+// ResourceNames are the names of the resources, in the order they are loaded.
+var ResourceNames = []string{ResourceDict, ResourceIdf, ResourceStopWords}
+
+// Resources are the vocabulary of a storage in the jieba formats. Dict has a
+// word per line followed by its frequency and optionally its part of speech,
+// Idf has a word per line followed by its idf and StopWords a word per line.
+// The missing ones are read from the files of the working directory.
+type Resources struct {
+	Dict      []byte
+	Idf       []byte
+	StopWords []byte
+}
+
+var (
+	defaultResources     *Resources
+	defaultErrors        map[string]error
+	defaultResourcesOnce sync.Once
+)
+
+// DefaultResources returns the dict.txt, idf.txt and stop_words.txt files of
+// the working directory, they are read once. The missing or invalid ones are
+// left empty, see RequireDefaults.
+func DefaultResources() *Resources {
+	defaultResourcesOnce.Do(func() {
+		defaultResources = &Resources{}
+		defaultErrors = make(map[string]error)
+		for _, name := range ResourceNames {
+			file := defaultResourceFile(name)
+			data, err := ioutil.ReadFile(file)
+			if err == nil {
+				err = ValidateResource(name, data)
+			}
+			if err != nil {
+				defaultErrors[name] = fmt.Errorf("could not load the default %s resource %s: %s", name, file, err.Error())
+				continue
+			}
+			defaultResources.Set(name, data)
+		}
+	})
+	return defaultResources
+}
+
+// RequireDefaults returns the error of the first default resource used in
+// place of a missing one that could not be loaded.
+func (resources *Resources) RequireDefaults() error {
+	DefaultResources()
+	for _, name := range ResourceNames {
+		if resources.Get(name) == nil && defaultErrors[name] != nil {
+			return defaultErrors[name]
+		}
+	}
+	return nil
+}
+
+// ReadResources reads and validates the resource files, the default resource
+// is kept when a file is empty. A missing file is an error.
+func ReadResources(dictFile, idfFile, stopWordsFile string) (*Resources, error) {
+	resources := &Resources{}
+	for i, file := range []string{dictFile, idfFile, stopWordsFile} {
+		if file == "" {
+			continue
+		}
+
+		name := ResourceNames[i]
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the %s resource: %s", name, err.Error())
+		}
+		if err := ValidateResource(name, data); err != nil {
+			return nil, fmt.Errorf("invalid %s resource %s: %s", name, file, err.Error())
+		}
+		resources.Set(name, data)
+	}
+	return resources, nil
+}
+
+func defaultResourceFile(name string) string {
+	switch name {
+	case ResourceDict:
+		return dictFile
+	case ResourceIdf:
+		return idfFile
+	default:
+		return stopWordsFile
+	}
+}
+
+// Get returns the resource of the name.
+func (resources *Resources) Get(name string) []byte {
+	switch name {
+	case ResourceDict:
+		return resources.Dict
+	case ResourceIdf:
+		return resources.Idf
+	case ResourceStopWords:
+		return resources.StopWords
+	default:
+		return nil
+	}
+}
+
+// Set replaces the resource of the name.
+func (resources *Resources) Set(name string, data []byte) {
+	switch name {
+	case ResourceDict:
+		resources.Dict = data
+	case ResourceIdf:
+		resources.Idf = data
+	case ResourceStopWords:
+		resources.StopWords = data
+	}
+}
+
+// Validate checks every resource that is set.
+func (resources *Resources) Validate() error {
+	for _, name := range ResourceNames {
+		if data := resources.Get(name); data != nil {
+			if err := ValidateResource(name, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withDefaults returns the resources, with the default ones for those missing.
+func (resources *Resources) withDefaults() *Resources {
+	defaults := DefaultResources()
+	if resources == nil {
+		return defaults
+	}
+
+	complete := *resources
+	for _, name := range ResourceNames {
+		if complete.Get(name) == nil {
+			complete.Set(name, defaults.Get(name))
+		}
+	}
+	return &complete
+}
+
+// ValidateResource checks the format of a resource, the errors give the line
+// of the first invalid entry.
+func ValidateResource(name string, data []byte) error {
+	switch name {
+	case ResourceDict:
+		return scanResource(name, data, func(fields []string) error {
+			if len(fields) > 1 {
+				if _, err := strconv.ParseFloat(fields[1], 64); err != nil {
+					return fmt.Errorf("invalid frequency '%s'", fields[1])
+				}
+			}
+			return nil
+		})
+	case ResourceIdf:
+		return scanResource(name, data, func(fields []string) error {
+			if len(fields) < 2 {
+				return fmt.Errorf("missing the idf of '%s'", fields[0])
+			}
+			if _, err := strconv.ParseFloat(fields[1], 64); err != nil {
+				return fmt.Errorf("invalid idf '%s'", fields[1])
+			}
+			return nil
+		})
+	case ResourceStopWords:
+		return scanResource(name, data, func([]string) error {
+			return nil
+		})
+	default:
+		return fmt.Errorf("unknown resource '%s'", name)
+	}
+}
+
+// scanResource calls entry with the fields of every line that is not blank.
+func scanResource(name string, data []byte, entry func([]string) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if len(fields) == 0 {
+			continue
+		}
+		if err := entry(fields); err != nil {
+			return fmt.Errorf("%s line %d: %s", name, line, err.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %s", name, err.Error())
+	}
+	return nil
+}
+
+// parseIdf reads a valid idf resource.
+func parseIdf(data []byte) *idfTable {
+	table := newIdfTable()
+	scanResource(ResourceIdf, data, func(fields []string) error {
+		table.frequencies[fields[0]], _ = strconv.ParseFloat(fields[1], 64)
+		return nil
+	})
+	table.updateMedian()
+	return table
+}
+
+// parseStopWords reads the stop words, with the ones jieba drops by default.
+func parseStopWords(data []byte) map[string]bool {
+	stopWords := make(map[string]bool, len(englishStopWords))
+	for word := range englishStopWords {
+		stopWords[word] = true
+	}
+	scanResource(ResourceStopWords, data, func(fields []string) error {
+		stopWords[strings.ToLower(fields[0])] = true
+		return nil
+	})
+	return stopWords
+}
+
+// writeTempResource writes a resource to a temporary file, for the libraries
+// only loading files. The caller removes the file.
+func writeTempResource(name string, data []byte) (string, error) {
+	file, err := ioutil.TempFile("", name)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
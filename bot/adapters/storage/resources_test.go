@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateResource(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{ResourceDict, "重置 100 v\n\n密码 50\nwifi\n", ""},
+		{ResourceDict, "重置 100\n密码 many\n", "dict line 2"},
+		{ResourceIdf, "\ufeff密码 8.5\n重置 7\n", ""},
+		{ResourceIdf, "密码 8.5\n重置\n", "idf line 2"},
+		{ResourceStopWords, "的\nthe\n", ""},
+		{"unknown", "", "unknown resource"},
+	}
+
+	for _, test := range tests {
+		err := ValidateResource(test.name, []byte(test.data))
+		if test.err == "" && err != nil {
+			t.Errorf("%s %q: unexpected error %v", test.name, test.data, err)
+		} else if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%s %q: expected an error with %q, got %v", test.name, test.data, test.err, err)
+		}
+	}
+}
+
+func TestReadResources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idf := filepath.Join(dir, "idf.txt")
+	if err := ioutil.WriteFile(idf, []byte("密码 8.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := ReadResources("", idf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources.Dict != nil || resources.StopWords != nil || string(resources.Idf) != "密码 8.5\n" {
+		t.Errorf("expected only the idf resource, got %+v", resources)
+	}
+
+	if _, err := ReadResources(filepath.Join(dir, "missing.txt"), "", ""); err == nil ||
+		!strings.Contains(err.Error(), "dict") {
+		t.Errorf("expected an error for the missing dictionary, got %v", err)
+	}
+}
+
+func TestResources_RequireDefaults(t *testing.T) {
+	// the tests run in a directory without default resources
+	if err := (&Resources{Idf: []byte("密码 8.5\n")}).RequireDefaults(); err == nil ||
+		!strings.Contains(err.Error(), dictFile) {
+		t.Errorf("expected an error for the missing default dictionary, got %v", err)
+	}
+
+	resources := &Resources{Dict: []byte{}, Idf: []byte{}, StopWords: []byte{}}
+	if err := resources.RequireDefaults(); err != nil {
+		t.Errorf("expected no error without default resources, got %v", err)
+	}
+}
+
+func TestMemoryStorage_SetResources(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.SetTokenizer(NewWordTokenizer())
+	err := storage.SetResources(&Resources{
+		Idf:       []byte("printer 9\nreset 2\nwifi 1\n"),
+		StopWords: []byte("How\nmy\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := storage.queryTags("how do i reset my printer")
+	if want := []string{"printer", "do", "reset"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("expected the keywords weighted with the resources, got %q", tags)
+	}
+
+	if err := storage.SetResources(&Resources{Idf: []byte("printer\n")}); err == nil {
+		t.Error("expected an error for an invalid idf")
+	}
+	if idf := storage.Idf("printer"); idf != 9 {
+		t.Errorf("expected the invalid resources to be ignored, got the idf %v", idf)
+	}
+}
+
+func TestLoadJiebaTokenizer(t *testing.T) {
+	tokenizer, err := LoadJiebaTokenizer([]byte("重置密码 1000 v\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if words := tokenizer.Cut("重置密码"); !reflect.DeepEqual(words, []string{"重置密码"}) {
+		t.Errorf("expected the word of the dictionary, got %q", words)
+	}
+	if !tokenizer.Known("重置密码") {
+		t.Error("expected the word of the dictionary to be known")
+	}
+
+	if _, err := LoadJiebaTokenizer([]byte("重置密码 many\n")); err == nil {
+		t.Error("expected an error for an invalid dictionary")
+	}
+}
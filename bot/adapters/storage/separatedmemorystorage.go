@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/gob"
+	"io/ioutil"
 	"os"
 
 	"github.com/jeffdoubleyou/chatbot/bot/nlp"
@@ -11,24 +13,21 @@ type separatedMemoryStorage struct {
 	filepath           string
 	declarativeStorage GobStorage
 	questionStorage    GobStorage
+	// normalizer is applied before choosing the storage of a sentence, so
+	// that its normalized form goes to the same storage.
+	normalizer nlp.Normalizer
 }
 
 func NewSeparatedMemoryStorage(filepath string) (*separatedMemoryStorage, error) {
 	var declarativeStorage, questionStorage GobStorage
 
 	if _, err := os.Stat(filepath); err == nil {
-		f, err := os.Open(filepath)
+		data, err := ioutil.ReadFile(filepath)
 		if err != nil {
 			return nil, err
 		}
-		defer f.Close()
 
-		decoder := gob.NewDecoder(f)
-		if declarativeStorage, err = RestoreMemoryStorage(decoder); err != nil {
-			return nil, err
-		}
-
-		if questionStorage, err = RestoreMemoryStorage(decoder); err != nil {
+		if declarativeStorage, questionStorage, err = restoreSeparatedStorages(data); err != nil {
 			return nil, err
 		}
 	} else {
@@ -43,6 +42,37 @@ func NewSeparatedMemoryStorage(filepath string) (*separatedMemoryStorage, error)
 	}, nil
 }
 
+// restoreSeparatedStorages decodes both storages, falling back to the legacy
+// format for files written before answers became records.
+func restoreSeparatedStorages(data []byte) (GobStorage, GobStorage, error) {
+	declarativeStorage, questionStorage, err := restoreStorages(data, RestoreMemoryStorage)
+	if err == nil {
+		return declarativeStorage, questionStorage, nil
+	}
+
+	declarativeStorage, questionStorage, legacyErr := restoreStorages(data, RestoreLegacyMemoryStorage)
+	if legacyErr != nil {
+		return nil, nil, err
+	}
+
+	return declarativeStorage, questionStorage, nil
+}
+
+func restoreStorages(data []byte, restore func(*gob.Decoder) (*memoryStorage, error)) (GobStorage, GobStorage, error) {
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+	declarativeStorage, err := restore(decoder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	questionStorage, err := restore(decoder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return declarativeStorage, questionStorage, nil
+}
+
 func (storage *separatedMemoryStorage) BuildIndex() {
 	storage.declarativeStorage.BuildIndex()
 	storage.questionStorage.BuildIndex()
@@ -52,15 +82,17 @@ func (storage *separatedMemoryStorage) Count() int {
 	return storage.declarativeStorage.Count() + storage.questionStorage.Count()
 }
 
-func (storage *separatedMemoryStorage) Find(sentence string, context ...string) (map[string]int, bool) {
+func (storage *separatedMemoryStorage) Find(sentence string, context ...string) ([]Record, bool) {
+	sentence = storage.Normalize(sentence)
 	if nlp.IsQuestion(sentence) {
-		return storage.questionStorage.Find(sentence)
+		return storage.questionStorage.Find(sentence, context...)
 	} else {
-		return storage.declarativeStorage.Find(sentence)
+		return storage.declarativeStorage.Find(sentence, context...)
 	}
 }
 
 func (storage *separatedMemoryStorage) Search(sentence string, context ...string) []string {
+	sentence = storage.Normalize(sentence)
 	if nlp.IsQuestion(sentence) {
 		return storage.questionStorage.Search(sentence)
 	} else {
@@ -68,7 +100,96 @@ func (storage *separatedMemoryStorage) Search(sentence string, context ...string
 	}
 }
 
+func (storage *separatedMemoryStorage) SearchExpanded(sentence string, context ...string) ([]string, map[string][]Expansion) {
+	sentence = storage.Normalize(sentence)
+	if nlp.IsQuestion(sentence) {
+		return storage.questionStorage.SearchExpanded(sentence)
+	} else {
+		return storage.declarativeStorage.SearchExpanded(sentence)
+	}
+}
+
+func (storage *separatedMemoryStorage) Correct(sentence string) (string, bool) {
+	sentence = storage.Normalize(sentence)
+	if nlp.IsQuestion(sentence) {
+		return storage.questionStorage.Correct(sentence)
+	} else {
+		return storage.declarativeStorage.Correct(sentence)
+	}
+}
+
+func (storage *separatedMemoryStorage) SetSynonyms(synonyms *Synonyms, indexed bool) {
+	storage.declarativeStorage.SetSynonyms(synonyms, indexed)
+	storage.questionStorage.SetSynonyms(synonyms, indexed)
+}
+
+func (storage *separatedMemoryStorage) Tokens(sentence string) []string {
+	return storage.questionStorage.Tokens(sentence)
+}
+
+func (storage *separatedMemoryStorage) Idf(term string) float64 {
+	return storage.questionStorage.Idf(term)
+}
+
+func (storage *separatedMemoryStorage) SetNormalizer(normalizer nlp.Normalizer) {
+	storage.normalizer = normalizer
+	storage.declarativeStorage.SetNormalizer(normalizer)
+	storage.questionStorage.SetNormalizer(normalizer)
+}
+
+func (storage *separatedMemoryStorage) SetTokenizer(tokenizer Tokenizer) {
+	storage.declarativeStorage.SetTokenizer(tokenizer)
+	storage.questionStorage.SetTokenizer(tokenizer)
+}
+
+func (storage *separatedMemoryStorage) SetResources(resources *Resources) error {
+	if err := storage.declarativeStorage.SetResources(resources); err != nil {
+		return err
+	}
+	return storage.questionStorage.SetResources(resources)
+}
+
+func (storage *separatedMemoryStorage) SetIdfOptions(options IdfOptions) error {
+	if err := storage.declarativeStorage.SetIdfOptions(options); err != nil {
+		return err
+	}
+	return storage.questionStorage.SetIdfOptions(options)
+}
+
+func (storage *separatedMemoryStorage) SetStopWordOverrides(included, excluded []string) {
+	storage.declarativeStorage.SetStopWordOverrides(included, excluded)
+	storage.questionStorage.SetStopWordOverrides(included, excluded)
+}
+
+// StopWords are the stop words of the questions.
+func (storage *separatedMemoryStorage) StopWords() StopWords {
+	return storage.questionStorage.StopWords()
+}
+
+// IdfReport is the report of the questions, they are the ones weighted with
+// their idf.
+func (storage *separatedMemoryStorage) IdfReport() IdfReport {
+	return storage.questionStorage.IdfReport()
+}
+
+func (storage *separatedMemoryStorage) Normalize(sentence string) string {
+	if storage.normalizer == nil {
+		return sentence
+	}
+	return storage.normalizer(sentence)
+}
+
+// SaveModel keeps the models with the questions.
+func (storage *separatedMemoryStorage) SaveModel(name string, data []byte) error {
+	return storage.questionStorage.SaveModel(name, data)
+}
+
+func (storage *separatedMemoryStorage) LoadModel(name string) ([]byte, bool, error) {
+	return storage.questionStorage.LoadModel(name)
+}
+
 func (storage *separatedMemoryStorage) Remove(sentence string) {
+	sentence = storage.Normalize(sentence)
 	if nlp.IsQuestion(sentence) {
 		storage.questionStorage.Remove(sentence)
 	} else {
@@ -94,7 +215,8 @@ func (storage *separatedMemoryStorage) Sync() error {
 	return storage.questionStorage.Sync()
 }
 
-func (storage *separatedMemoryStorage) Update(sentence string, responses map[string]int) {
+func (storage *separatedMemoryStorage) Update(sentence string, responses []Record) {
+	sentence = storage.Normalize(sentence)
 	if nlp.IsQuestion(sentence) {
 		storage.questionStorage.Update(sentence, responses)
 	} else {
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSeparatedMemoryStorage_MigratesLegacyFormat(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "corpus.gob")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder := gob.NewEncoder(f)
+	legacy := []map[string]map[string]int{
+		{"hello": {"hi there": 2}},
+		{"你好吗？": {"你好吗？$$$$很好$$$$7$$$$greeting": 1}},
+	}
+	for _, responses := range legacy {
+		for _, value := range []interface{}{[]string{}, responses, map[string][]int{}} {
+			if err := encoder.Encode(value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	f.Close()
+
+	store, err := NewSeparatedMemoryStorage(file)
+	if err != nil {
+		t.Fatalf("could not restore legacy storage: %s", err.Error())
+	}
+
+	records, ok := store.Find("hello")
+	if !ok || len(records) != 1 {
+		t.Fatalf("expected one record for 'hello', got %v", records)
+	}
+	if records[0].Answer != "hi there" || records[0].Occurrence != 2 || records[0].CorpusId != 0 {
+		t.Errorf("unexpected plain record %+v", records[0])
+	}
+
+	records, ok = store.Find("你好吗？")
+	if !ok || len(records) != 1 {
+		t.Fatalf("expected one record for '你好吗？', got %v", records)
+	}
+	expected := Record{Question: "你好吗？", Answer: "很好", CorpusId: 7, Context: "greeting", Occurrence: 1}
+	if records[0].Question != expected.Question || records[0].Answer != expected.Answer ||
+		records[0].CorpusId != expected.CorpusId || records[0].Context != expected.Context {
+		t.Errorf("expected migrated record %+v, got %+v", expected, records[0])
+	}
+
+	if records, _ = store.Find("你好吗？", "other"); len(records) != 0 {
+		t.Errorf("expected no records in another context, got %v", records)
+	}
+}
+
+func TestSeparatedMemoryStorage_SyncRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "corpus.gob")
+	store, err := NewSeparatedMemoryStorage(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := Record{
+		Question:   "怎么创建分支？",
+		Answer:     "answer with $$$$ inside",
+		CorpusId:   3,
+		Class:      "git",
+		Data:       map[string]interface{}{"link": "https://example.com", "tags": []interface{}{"a"}},
+		Occurrence: 1,
+	}
+	store.Update(record.Question, []Record{record})
+	store.SaveModel("intent", []byte("model"))
+	if err := store.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := NewSeparatedMemoryStorage(file)
+	if err != nil {
+		t.Fatalf("could not restore storage: %s", err.Error())
+	}
+
+	records, ok := restored.Find(record.Question)
+	if !ok || len(records) != 1 {
+		t.Fatalf("expected one record, got %v", records)
+	}
+	if records[0].Answer != record.Answer || records[0].Class != record.Class ||
+		records[0].Data["link"] != record.Data["link"] {
+		t.Errorf("expected %+v, got %+v", record, records[0])
+	}
+	if model, ok, _ := restored.LoadModel("intent"); !ok || string(model) != "model" {
+		t.Errorf("expected the model to be restored, got '%s'", model)
+	}
+}
+
+func TestSeparatedMemoryStorage_RestoresVersion2(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "corpus.gob")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoder := gob.NewEncoder(f)
+	question := "where is the printer?"
+	for _, keys := range [][]string{{question}, nil} {
+		responses := make(map[string][]Record)
+		for _, key := range keys {
+			responses[key] = []Record{{Question: key, Answer: "second floor", CorpusId: 1}}
+		}
+		for _, value := range []interface{}{storeHeader{Version: 2}, keys, responses, map[string][]int{}} {
+			if err := encoder.Encode(value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	f.Close()
+
+	restored, err := NewSeparatedMemoryStorage(file)
+	if err != nil {
+		t.Fatalf("could not restore a version 2 storage: %s", err.Error())
+	}
+	if records, ok := restored.Find(question); !ok || len(records) != 1 {
+		t.Errorf("expected the record, got %v", records)
+	}
+	if _, ok, _ := restored.LoadModel("intent"); ok {
+		t.Error("expected no models")
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
+)
+
+const (
+	// words shorter than minCorrectedLength are never corrected
+	minCorrectedLength = 3
+	// words up to shortWordLength may have one typo, longer ones two
+	shortWordLength = 5
+)
+
+// spellingOptions counts a substitution as a single typo.
+var spellingOptions = nlp.Options{
+	InsCost: 1,
+	DelCost: 1,
+	SubCost: 1,
+	Matches: nlp.DefaultOptions.Matches,
+}
+
+// correctWords replaces the latin words of the query that are not known with
+// the closest term of the vocabulary. The closest terms are ranked by edit
+// distance, then by frequency.
+func correctWords(query string, known func(string) bool, vocabulary map[string][]int) (string, bool) {
+	var builder strings.Builder
+	var corrected bool
+	for start := 0; start < len(query); {
+		end := start
+		for end < len(query) {
+			r, width := utf8.DecodeRuneInString(query[end:])
+			if !isLatinWord(r) {
+				break
+			}
+			end += width
+		}
+		if end == start {
+			_, width := utf8.DecodeRuneInString(query[start:])
+			builder.WriteString(query[start : start+width])
+			start += width
+			continue
+		}
+
+		word := query[start:end]
+		if correction, ok := correctWord(word, known, vocabulary); ok {
+			builder.WriteString(correction)
+			corrected = true
+		} else {
+			builder.WriteString(word)
+		}
+		start = end
+	}
+	return builder.String(), corrected
+}
+
+func correctWord(word string, known func(string) bool, vocabulary map[string][]int) (string, bool) {
+	length := utf8.RuneCountInString(word)
+	if length < minCorrectedLength || !isLatinText(word) || known(word) {
+		return "", false
+	}
+
+	maxDistance := 1
+	if length > shortWordLength {
+		maxDistance = 2
+	}
+
+	source := []rune(word)
+	var best string
+	bestDistance := maxDistance + 1
+	var bestFrequency int
+	for term, ids := range vocabulary {
+		if abs(utf8.RuneCountInString(term)-length) > maxDistance || !isLatinText(term) {
+			continue
+		}
+
+		distance := nlp.DistanceForStrings(source, []rune(term), spellingOptions)
+		if distance < bestDistance || distance == bestDistance &&
+			(len(ids) > bestFrequency || len(ids) == bestFrequency && term < best) {
+			best, bestDistance, bestFrequency = term, distance, len(ids)
+		}
+	}
+
+	return best, best != ""
+}
+
+// isLatinText reports whether the text is a single latin word.
+func isLatinText(text string) bool {
+	for _, r := range text {
+		if !isLatinWord(r) {
+			return false
+		}
+	}
+	return text != ""
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,32 @@
+package storage
+
+import "testing"
+
+func TestMemoryStorage_Correct(t *testing.T) {
+	storage := NewMemoryStorage()
+	for _, key := range []string{"reset the password", "printer driver", "password policy", "change the passport"} {
+		storage.Update(key, []Record{{Question: key, Answer: "answer"}})
+	}
+	storage.BuildIndex()
+
+	tests := []struct {
+		query     string
+		corrected string
+		ok        bool
+	}{
+		{"pasword", "password", true},
+		{"how to reset my pasword", "how to reset my password", true},
+		{"printre drivr", "printer driver", true},
+		{"password", "password", false},
+		{"qwertyuiop", "qwertyuiop", false},
+		{"the pas", "the pas", false},
+		{"密码 pasword", "密码 password", true},
+	}
+
+	for _, test := range tests {
+		corrected, ok := storage.Correct(test.query)
+		if corrected != test.corrected || ok != test.ok {
+			t.Errorf("Correct(%q) = %q, %v, want %q, %v", test.query, corrected, ok, test.corrected, test.ok)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/go-xorm/xorm"
+)
+
+type (
+	// ResponseRow is a Record of a project persisted by sqlStorage. QuestionKey
+	// is the normalized question it is found with, looked up by its hash, and
+	// Question is the question of the record as written.
+	ResponseRow struct {
+		Id           int64                  `xorm:"pk autoincr 'id'"`
+		Project      string                 `xorm:"varchar(255) notnull index(storage_response_question) 'project'"`
+		QuestionHash string                 `xorm:"varchar(40) notnull index(storage_response_question) 'question_hash'"`
+		QuestionKey  string                 `xorm:"varchar(2048) notnull default '' 'question_key'"`
+		Question     string                 `xorm:"varchar(2048) notnull 'question'"`
+		Answer       string                 `xorm:"text notnull 'answer'"`
+		CorpusId     int                    `xorm:"int notnull default 0 'corpus_id'"`
+		Context      string                 `xorm:"varchar(255) notnull default '' 'context'"`
+		Contextual   bool                   `xorm:"notnull default 0 'contextual'"`
+		State        string                 `xorm:"varchar(255) notnull default '' 'state'"`
+		Class        string                 `xorm:"varchar(255) notnull default '' 'class'"`
+		Data         map[string]interface{} `xorm:"text json 'data'"`
+		Occurrence   int                    `xorm:"int notnull default 0 'occurrence'"`
+	}
+
+	// IndexRow is one posting of the inverted keyword index of a project.
+	IndexRow struct {
+		Id           int64  `xorm:"pk autoincr 'id'"`
+		Project      string `xorm:"varchar(255) notnull index(storage_index_term) index(storage_index_question) 'project'"`
+		Term         string `xorm:"varchar(255) notnull index(storage_index_term) 'term'"`
+		QuestionHash string `xorm:"varchar(40) notnull index(storage_index_question) 'question_hash'"`
+		Question     string `xorm:"varchar(2048) notnull 'question'"`
+	}
+
+	// ModelRow is a model of a project persisted by sqlStorage.
+	ModelRow struct {
+		Id      int64  `xorm:"pk autoincr 'id'"`
+		Project string `xorm:"varchar(255) notnull unique(storage_model_name) 'project'"`
+		Name    string `xorm:"varchar(255) notnull unique(storage_model_name) 'name'"`
+		Data    []byte `xorm:"blob 'data'"`
+	}
+
+	questionMatches struct {
+		Question string
+		Matches  int
+	}
+
+	// sqlStorage keeps the responses and the index of one project in SQL
+	// tables, several servers can share them.
+	sqlStorage struct {
+		*keywords
+		engine  *xorm.Engine
+		project string
+	}
+)
+
+func (ResponseRow) TableName() string {
+	return "storage_response"
+}
+
+func (IndexRow) TableName() string {
+	return "storage_index"
+}
+
+func (ModelRow) TableName() string {
+	return "storage_model"
+}
+
+func NewSqlStorage(engine *xorm.Engine, project string) (*sqlStorage, error) {
+	if err := engine.Sync2(&ResponseRow{}, &IndexRow{}, &ModelRow{}); err != nil {
+		return nil, err
+	}
+
+	return &sqlStorage{
+		keywords: newKeywords(),
+		engine:   engine,
+		project:  project,
+	}, nil
+}
+
+func (storage *sqlStorage) BuildIndex() {
+	var questions []string
+	if err := storage.engine.Table(&ResponseRow{}).Distinct("question_key").
+		Where("project = ?", storage.project).Find(&questions); err != nil {
+		fmt.Printf("Could not load questions of project %s: %s\n", storage.project, err.Error())
+		return
+	}
+	storage.updateCorpusIdf(questions, storage)
+
+	session := storage.engine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		fmt.Printf("Could not build index of project %s: %s\n", storage.project, err.Error())
+		return
+	}
+
+	if _, err := session.Delete(&IndexRow{Project: storage.project}); err != nil {
+		session.Rollback()
+		fmt.Printf("Could not clear index of project %s: %s\n", storage.project, err.Error())
+		return
+	}
+
+	for _, question := range questions {
+		if err := storage.insertIndex(session, question); err != nil {
+			session.Rollback()
+			fmt.Printf("Could not index '%s': %s\n", question, err.Error())
+			return
+		}
+	}
+
+	if err := session.Commit(); err != nil {
+		fmt.Printf("Could not build index of project %s: %s\n", storage.project, err.Error())
+	}
+}
+
+func (storage *sqlStorage) Count() int {
+	count, err := storage.engine.Table(&ResponseRow{}).Where("project = ?", storage.project).
+		Select("count(distinct question_hash)").Count()
+	if err != nil {
+		fmt.Printf("Could not count questions of project %s: %s\n", storage.project, err.Error())
+		return 0
+	}
+	return int(count)
+}
+
+func (storage *sqlStorage) Find(text string, context ...string) ([]Record, bool) {
+	text = storage.Normalize(text)
+	var rows []ResponseRow
+	if err := storage.engine.Where("project = ? and question_hash = ? and question_key = ?",
+		storage.project, questionHash(text), text).Asc("id").Find(&rows); err != nil {
+		fmt.Printf("Could not find '%s': %s\n", text, err.Error())
+		return nil, false
+	}
+
+	if len(rows) == 0 {
+		return nil, false
+	}
+
+	var records []Record
+	for _, row := range rows {
+		record := row.record()
+		if len(context) == 1 && !record.MatchContext(context[0]) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, true
+}
+
+func (storage *sqlStorage) Search(key string, context ...string) []string {
+	key = strings.ToLower(storage.Normalize(key))
+	questions := storage.searchTerms(storage.queryTags(key))
+	if len(questions) == 0 {
+		questions = storage.searchTerms(storage.queryWords(key))
+	}
+	return questions
+}
+
+func (storage *sqlStorage) LoadModel(name string) ([]byte, bool, error) {
+	row := ModelRow{Project: storage.project, Name: name}
+	ok, err := storage.engine.Get(&row)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return row.Data, true, nil
+}
+
+func (storage *sqlStorage) SaveModel(name string, data []byte) error {
+	row := ModelRow{Project: storage.project, Name: name, Data: data}
+	updated, err := storage.engine.Where("project = ? and name = ?", storage.project, name).Cols("data").Update(&row)
+	if err != nil || updated > 0 {
+		return err
+	}
+	_, err = storage.engine.Insert(&row)
+	return err
+}
+
+func (storage *sqlStorage) SetIdfOptions(options IdfOptions) error {
+	return storage.setIdfOptions(options, storage)
+}
+
+func (storage *sqlStorage) Persistent() bool {
+	return true
+}
+
+func (storage *sqlStorage) Remove(text string) {
+	text = storage.Normalize(text)
+	session := storage.engine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		fmt.Printf("Could not remove '%s': %s\n", text, err.Error())
+		return
+	}
+
+	hash := questionHash(text)
+	if _, err := session.Where("question_key = ?", text).Delete(&ResponseRow{Project: storage.project, QuestionHash: hash}); err != nil {
+		session.Rollback()
+		fmt.Printf("Could not remove '%s': %s\n", text, err.Error())
+		return
+	}
+
+	if _, err := session.Where("question = ?", text).Delete(&IndexRow{Project: storage.project, QuestionHash: hash}); err != nil {
+		session.Rollback()
+		fmt.Printf("Could not remove '%s' from index: %s\n", text, err.Error())
+		return
+	}
+
+	if err := session.Commit(); err != nil {
+		fmt.Printf("Could not remove '%s': %s\n", text, err.Error())
+	}
+}
+
+// Sync does nothing, every change is written when it is made.
+func (storage *sqlStorage) Sync() error {
+	return nil
+}
+
+func (storage *sqlStorage) Update(text string, responses []Record) {
+	text = storage.Normalize(text)
+	session := storage.engine.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		fmt.Printf("Could not update '%s': %s\n", text, err.Error())
+		return
+	}
+
+	hash := questionHash(text)
+	existing, err := session.Where("question_key = ?", text).Delete(&ResponseRow{Project: storage.project, QuestionHash: hash})
+	if err != nil {
+		session.Rollback()
+		fmt.Printf("Could not update '%s': %s\n", text, err.Error())
+		return
+	}
+
+	for _, record := range responses {
+		row := newResponseRow(storage.project, text, record)
+		if _, err := session.Insert(&row); err != nil {
+			session.Rollback()
+			fmt.Printf("Could not update '%s': %s\n", text, err.Error())
+			return
+		}
+	}
+
+	if existing == 0 {
+		if err := storage.insertIndex(session, text); err != nil {
+			session.Rollback()
+			fmt.Printf("Could not index '%s': %s\n", text, err.Error())
+			return
+		}
+	}
+
+	if err := session.Commit(); err != nil {
+		fmt.Printf("Could not update '%s': %s\n", text, err.Error())
+	}
+}
+
+func (storage *sqlStorage) insertIndex(session *xorm.Session, question string) error {
+	var rows []IndexRow
+	hash := questionHash(question)
+	for _, term := range storage.terms(question) {
+		rows = append(rows, IndexRow{
+			Project:      storage.project,
+			Term:         term,
+			QuestionHash: hash,
+			Question:     question,
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	_, err := session.Insert(&rows)
+	return err
+}
+
+// searchTerms returns the questions matching most of the terms, shortest
+// questions first among the same number of matches.
+func (storage *sqlStorage) searchTerms(terms []string) []string {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []questionMatches
+	if err := storage.engine.Table(&IndexRow{}).Select("question, count(*) as matches").
+		Where("project = ?", storage.project).In("term", terms).GroupBy("question").
+		OrderBy("matches desc, length(question) asc").Limit(maxSearchResults).Find(&matches); err != nil {
+		fmt.Printf("Could not search %v: %s\n", terms, err.Error())
+		return nil
+	}
+
+	questions := make([]string, len(matches))
+	for i := range matches {
+		questions[i] = matches[i].Question
+	}
+	return questions
+}
+
+func newResponseRow(project, key string, record Record) ResponseRow {
+	return ResponseRow{
+		Project:      project,
+		QuestionHash: questionHash(key),
+		QuestionKey:  key,
+		Question:     record.Question,
+		Answer:       record.Answer,
+		CorpusId:     record.CorpusId,
+		Context:      record.Context,
+		Contextual:   record.Contextual,
+		State:        record.State,
+		Class:        record.Class,
+		Data:         record.Data,
+		Occurrence:   record.Occurrence,
+	}
+}
+
+func (row ResponseRow) record() Record {
+	return Record{
+		Question:   row.Question,
+		Answer:     row.Answer,
+		CorpusId:   row.CorpusId,
+		Context:    row.Context,
+		Contextual: row.Contextual,
+		State:      row.State,
+		Class:      row.Class,
+		Data:       row.Data,
+		Occurrence: row.Occurrence,
+	}
+}
+
+func questionHash(question string) string {
+	sum := sha1.Sum([]byte(question))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestSqlStorage(t *testing.T, project string) *sqlStorage {
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "chatbot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		engine.Close()
+	})
+
+	storage, err := NewSqlStorage(engine, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage
+}
+
+func TestSqlStorage(t *testing.T) {
+	testStorageAdapter(t, func(t *testing.T) StorageAdapter {
+		return newTestSqlStorage(t, "IT")
+	})
+}
+
+func TestSqlStorage_SharedAcrossInstances(t *testing.T) {
+	storage := newTestSqlStorage(t, "IT")
+	storage.Update("where is the printer?", []Record{{Question: "where is the printer?", Answer: "second floor", CorpusId: 3}})
+	storage.BuildIndex()
+
+	replica, err := NewSqlStorage(storage.engine, "IT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := replica.Search("printer"); len(keys) != 1 {
+		t.Errorf("expected the replica to search the shared index, got %v", keys)
+	}
+
+	other, err := NewSqlStorage(storage.engine, "HR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := other.Count(); count != 0 {
+		t.Errorf("expected projects to be separated, got %d questions", count)
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// StopWordsDetecting is implemented by the storages detecting the terms
+	// of too many keys to tell them apart when the index is built. The stop
+	// words are only looked up when the other terms of a query match no key.
+	StopWordsDetecting interface {
+		StopWords() StopWords
+		// SetStopWordOverrides sets the terms always or never stop words,
+		// whether they are detected or not.
+		SetStopWordOverrides(included, excluded []string)
+	}
+
+	// StopWords are the stop words of a storage, Terms are the detected ones
+	// with the included ones and without the excluded ones.
+	StopWords struct {
+		Detected []DetectedStopWord `json:"detected"`
+		Included []string           `json:"included"`
+		Excluded []string           `json:"excluded"`
+		Terms    []string           `json:"terms"`
+	}
+
+	// DetectedStopWord is a term with the number of keys it is indexed for.
+	DetectedStopWord struct {
+		Term string `json:"term"`
+		Keys int    `json:"keys"`
+	}
+
+	// stopWords are the detected stop words of a storage and its overrides.
+	stopWords struct {
+		detected map[string]int
+		included map[string]bool
+		excluded map[string]bool
+	}
+)
+
+// detectStopWords returns the terms indexed for more than 1/thresholdForStopWords
+// of the keys, with the number of keys. Nothing is detected with less than
+// minKeysForStopWords keys.
+func detectStopWords(indexes map[string][]int, keys int) map[string]int {
+	detected := make(map[string]int)
+	if keys < minKeysForStopWords {
+		return detected
+	}
+
+	for term, ids := range indexes {
+		if len(ids) > keys/thresholdForStopWords {
+			detected[term] = len(ids)
+		}
+	}
+	return detected
+}
+
+// contains reports whether the term is a stop word.
+func (words *stopWords) contains(term string) bool {
+	if words.excluded[term] {
+		return false
+	}
+	_, ok := words.detected[term]
+	return ok || words.included[term]
+}
+
+// report returns the stop words, the detected ones from the most common.
+func (words *stopWords) report() StopWords {
+	report := StopWords{
+		Detected: []DetectedStopWord{},
+		Included: sortedTerms(words.included),
+		Excluded: sortedTerms(words.excluded),
+		Terms:    []string{},
+	}
+	for term, keys := range words.detected {
+		report.Detected = append(report.Detected, DetectedStopWord{Term: term, Keys: keys})
+		if !words.excluded[term] {
+			report.Terms = append(report.Terms, term)
+		}
+	}
+	for term := range words.included {
+		if _, ok := words.detected[term]; !ok && !words.excluded[term] {
+			report.Terms = append(report.Terms, term)
+		}
+	}
+
+	sort.Slice(report.Detected, func(i, j int) bool {
+		if report.Detected[i].Keys != report.Detected[j].Keys {
+			return report.Detected[i].Keys > report.Detected[j].Keys
+		}
+		return report.Detected[i].Term < report.Detected[j].Term
+	})
+	sort.Strings(report.Terms)
+	return report
+}
+
+// termSet returns the lower-cased terms rewritten like the keys.
+func termSet(terms []string, normalize func(string) string) map[string]bool {
+	set := make(map[string]bool)
+	for _, term := range terms {
+		if term = strings.TrimSpace(strings.ToLower(normalize(term))); term != "" {
+			set[term] = true
+		}
+	}
+	return set
+}
+
+func sortedTerms(set map[string]bool) []string {
+	terms := make([]string, 0, len(set))
+	for term := range set {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
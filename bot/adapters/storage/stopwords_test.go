@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryStorage_StopWords(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.SetTokenizer(NewWordTokenizer())
+	for i := 0; i < minKeysForStopWords; i++ {
+		key := fmt.Sprintf("printer jam %d", i)
+		storage.Update(key, []Record{{Question: key, Answer: "answer"}})
+	}
+	storage.Update("password reset", []Record{{Question: "password reset", Answer: "answer"}})
+	storage.BuildIndex()
+
+	stopWords := storage.StopWords()
+	want := []DetectedStopWord{{Term: "jam", Keys: minKeysForStopWords}, {Term: "printer", Keys: minKeysForStopWords}}
+	if !reflect.DeepEqual(stopWords.Detected, want) {
+		t.Errorf("expected the terms of most keys, got %+v", stopWords.Detected)
+	}
+
+	if keys := storage.Search("printer password"); !reflect.DeepEqual(keys, []string{"password reset"}) {
+		t.Errorf("expected the stop words to be skipped when other terms match, got %d keys", len(keys))
+	}
+	if keys := storage.Search("printer"); len(keys) == 0 {
+		t.Error("expected the stop words to be looked up when nothing else matches")
+	}
+
+	storage.SetStopWordOverrides([]string{"Password"}, []string{"printer"})
+	stopWords = storage.StopWords()
+	if want := []string{"jam", "password"}; !reflect.DeepEqual(stopWords.Terms, want) {
+		t.Errorf("expected the overrides to apply, got %q", stopWords.Terms)
+	}
+	if keys := storage.Search("printer password"); len(keys) != maxSearchResults {
+		t.Errorf("expected the excluded term to be looked up, got %d keys", len(keys))
+	}
+
+	restored := NewMemoryStorage()
+	restored.restoreIndex(storage.keys, storage.indexes)
+	if detected := restored.StopWords().Detected; len(detected) != 2 {
+		t.Errorf("expected the stop words to be detected in the restored index, got %+v", detected)
+	}
+}
+
+func TestDetectStopWords(t *testing.T) {
+	indexes := map[string][]int{"the": {0, 1, 2}}
+	if detected := detectStopWords(indexes, 3); len(detected) != 0 {
+		t.Errorf("expected nothing to be detected with few keys, got %v", detected)
+	}
+}
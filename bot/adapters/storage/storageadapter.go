@@ -1,11 +1,85 @@
 package storage
 
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
+)
+
 type StorageAdapter interface {
 	BuildIndex()
 	Count() int
-	Find(string, ...string) (map[string]int, bool)
+	Find(string, ...string) ([]Record, bool)
 	Search(string, ...string) []string
 	Remove(string)
 	Sync() error
-	Update(string, map[string]int)
+	Update(string, []Record)
+}
+
+// Persistent is implemented by the storages that write every change through,
+// once trained they don't need to be trained again when they are opened.
+type Persistent interface {
+	Persistent() bool
+}
+
+// Normalizing is implemented by the storages normalizing the keys, the same
+// way when they are indexed and when they are looked up.
+type Normalizing interface {
+	SetNormalizer(nlp.Normalizer)
+	Normalize(string) string
+}
+
+// Tokenizing is implemented by the storages segmenting the keys with a
+// tokenizer, the storage must be indexed again when it or the resources
+// change.
+type Tokenizing interface {
+	SetTokenizer(Tokenizer)
+	// SetResources replaces the idf table and the stop words the keywords
+	// are weighted with.
+	SetResources(*Resources) error
+}
+
+// Expanding is implemented by the storages expanding the terms of the queries
+// with their synonyms.
+type Expanding interface {
+	// SetSynonyms replaces the synonyms, when they are indexed too the index
+	// must be built again.
+	SetSynonyms(synonyms *Synonyms, indexed bool)
+	// SearchExpanded is Search returning the expansions found in each key.
+	SearchExpanded(string, ...string) ([]string, map[string][]Expansion)
+}
+
+// Correcting is implemented by the storages correcting the misspelled words of
+// the queries with the words they index.
+type Correcting interface {
+	// Correct returns the normalized query with its misspelled words
+	// corrected, and whether any word was corrected.
+	Correct(string) (string, bool)
+}
+
+// ModelStore is implemented by the storages that keep the models trained from
+// their corpus, like the intent classifier, alongside the responses. Models
+// are saved with the storage, on Sync for the storages in memory.
+type ModelStore interface {
+	SaveModel(name string, data []byte) error
+	LoadModel(name string) ([]byte, bool, error)
+}
+
+// Open opens the storage file selected by its scheme or extension, "bolt://"
+// and ".bolt" files are bolt storages, anything else is a gob file.
+func Open(file string) (StorageAdapter, error) {
+	switch {
+	case strings.HasPrefix(file, "bolt://"):
+		return NewBoltStorage(strings.TrimPrefix(file, "bolt://"))
+	case strings.HasPrefix(file, "gob://"):
+		return NewSeparatedMemoryStorage(strings.TrimPrefix(file, "gob://"))
+	}
+
+	switch filepath.Ext(file) {
+	case ".bolt":
+		return NewBoltStorage(file)
+	default:
+		return NewSeparatedMemoryStorage(file)
+	}
 }
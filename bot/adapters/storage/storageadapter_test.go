@@ -0,0 +1,117 @@
+package storage
+
+import "testing"
+
+// testStorageAdapter runs the behaviour every StorageAdapter has to share.
+func testStorageAdapter(t *testing.T, newStorage func(t *testing.T) StorageAdapter) {
+	t.Run("empty", func(t *testing.T) {
+		storage := newStorage(t)
+		if count := storage.Count(); count != 0 {
+			t.Errorf("expected no questions, got %d", count)
+		}
+		if records, ok := storage.Find("anything?"); ok {
+			t.Errorf("expected no records, got %v", records)
+		}
+		if keys := storage.Search("anything"); len(keys) != 0 {
+			t.Errorf("expected no search results, got %v", keys)
+		}
+	})
+
+	t.Run("find and search", func(t *testing.T) {
+		storage := newStorage(t)
+		storage.Update("how to reset password?", []Record{
+			{Question: "how to reset password?", Answer: "use the portal", CorpusId: 1, Occurrence: 2},
+			{Question: "how to reset password?", Answer: "ask the admin", CorpusId: 2, Context: "admin", Occurrence: 1},
+		})
+		storage.Update("where is the printer?", []Record{
+			{Question: "where is the printer?", Answer: "second floor", CorpusId: 3, Class: "office",
+				Data: map[string]interface{}{"floor": "2"}, Occurrence: 1},
+		})
+		storage.BuildIndex()
+
+		if count := storage.Count(); count != 2 {
+			t.Errorf("expected 2 questions, got %d", count)
+		}
+
+		records, ok := storage.Find("how to reset password?")
+		if !ok || len(records) != 2 {
+			t.Fatalf("expected 2 records, got %v", records)
+		}
+
+		records, _ = storage.Find("how to reset password?", "")
+		if len(records) != 1 || records[0].Answer != "use the portal" {
+			t.Errorf("expected only the answer without context, got %v", records)
+		}
+
+		records, _ = storage.Find("how to reset password?", "admin")
+		if len(records) != 1 || records[0].Answer != "ask the admin" {
+			t.Errorf("expected only the answer in the admin context, got %v", records)
+		}
+
+		records, _ = storage.Find("where is the printer?")
+		if len(records) != 1 || records[0].Class != "office" || records[0].Data["floor"] != "2" {
+			t.Errorf("expected the record metadata to be kept, got %v", records)
+		}
+
+		if keys := storage.Search("printer"); len(keys) != 1 || keys[0] != "where is the printer?" {
+			t.Errorf("expected to find the printer question, got %v", keys)
+		}
+	})
+
+	t.Run("question as written", func(t *testing.T) {
+		storage := newStorage(t)
+		storage.Update("what is the wifi password?", []Record{{Question: "What's the WiFi password?", Answer: "ask IT", CorpusId: 5}})
+		if records, _ := storage.Find("what is the wifi password?"); len(records) != 1 || records[0].Question != "What's the WiFi password?" {
+			t.Errorf("expected the question of the record to be kept, got %v", records)
+		}
+	})
+
+	t.Run("edits after index", func(t *testing.T) {
+		storage := newStorage(t)
+		storage.Update("where is the printer?", []Record{{Question: "where is the printer?", Answer: "second floor", CorpusId: 3}})
+		storage.BuildIndex()
+
+		storage.Update("where is the scanner?", []Record{{Question: "where is the scanner?", Answer: "first floor", CorpusId: 4}})
+		if keys := storage.Search("scanner"); len(keys) != 1 || keys[0] != "where is the scanner?" {
+			t.Errorf("expected the new question to be searchable, got %v", keys)
+		}
+
+		storage.Update("where is the printer?", []Record{{Question: "where is the printer?", Answer: "third floor", CorpusId: 3}})
+		if records, _ := storage.Find("where is the printer?"); len(records) != 1 || records[0].Answer != "third floor" {
+			t.Errorf("expected the answer to be replaced, got %v", records)
+		}
+		if keys := storage.Search("printer"); len(keys) != 1 {
+			t.Errorf("expected the updated question once, got %v", keys)
+		}
+
+		storage.Remove("where is the printer?")
+		if records, ok := storage.Find("where is the printer?"); ok {
+			t.Errorf("expected the question to be removed, got %v", records)
+		}
+		if keys := storage.Search("printer"); len(keys) != 0 {
+			t.Errorf("expected the removed question not to be searchable, got %v", keys)
+		}
+		if count := storage.Count(); count != 1 {
+			t.Errorf("expected 1 question, got %d", count)
+		}
+	})
+
+	t.Run("models", func(t *testing.T) {
+		storage, ok := newStorage(t).(ModelStore)
+		if !ok {
+			t.Skip("the storage keeps no models")
+		}
+
+		if _, ok, err := storage.LoadModel("intent"); ok || err != nil {
+			t.Errorf("expected no model, got %v, %v", ok, err)
+		}
+		for _, data := range []string{"first", "second"} {
+			if err := storage.SaveModel("intent", []byte(data)); err != nil {
+				t.Fatal(err)
+			}
+			if model, ok, err := storage.LoadModel("intent"); !ok || err != nil || string(model) != data {
+				t.Errorf("expected model '%s', got '%s', %v", data, model, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type (
+	// Synonyms are the sets of interchangeable terms of a project, a term
+	// may be a phrase like "sign in".
+	Synonyms struct {
+		sets [][]string
+	}
+
+	// Expansion is a term of a question matched through one of its synonyms.
+	Expansion struct {
+		Term    string `json:"term"`
+		Synonym string `json:"synonym"`
+	}
+)
+
+// NewSynonyms lower-cases the terms of the sets, the sets with less than two
+// different terms are dropped.
+func NewSynonyms(sets [][]string) *Synonyms {
+	synonyms := &Synonyms{}
+	for _, set := range sets {
+		var terms []string
+		seen := make(map[string]bool)
+		for _, term := range set {
+			term = strings.ToLower(strings.TrimSpace(term))
+			if term != "" && !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+		if len(terms) > 1 {
+			synonyms.sets = append(synonyms.sets, terms)
+		}
+	}
+	return synonyms
+}
+
+// Len returns the number of synonym sets.
+func (synonyms *Synonyms) Len() int {
+	if synonyms == nil {
+		return 0
+	}
+	return len(synonyms.sets)
+}
+
+// normalized returns the synonyms with every term rewritten like the keys.
+func (synonyms *Synonyms) normalized(normalize func(string) string) *Synonyms {
+	if synonyms == nil {
+		return nil
+	}
+
+	sets := make([][]string, len(synonyms.sets))
+	for i, set := range synonyms.sets {
+		for _, term := range set {
+			sets[i] = append(sets[i], normalize(term))
+		}
+	}
+	return NewSynonyms(sets)
+}
+
+// expansions returns every synonym of the terms found in the lower-cased
+// text, except the ones the text already has.
+func (synonyms *Synonyms) expansions(text string) []Expansion {
+	if synonyms == nil {
+		return nil
+	}
+
+	var expansions []Expansion
+	seen := make(map[Expansion]bool)
+	for _, set := range synonyms.sets {
+		var found, missing []string
+		for _, term := range set {
+			if containsTerm(text, term) {
+				found = append(found, term)
+			} else {
+				missing = append(missing, term)
+			}
+		}
+
+		for _, term := range found {
+			for _, synonym := range missing {
+				expansion := Expansion{Term: term, Synonym: synonym}
+				if !seen[expansion] {
+					seen[expansion] = true
+					expansions = append(expansions, expansion)
+				}
+			}
+		}
+	}
+	return expansions
+}
+
+// matched returns the expansions whose synonym is in the lower-cased key.
+func matched(expansions []Expansion, key string) []Expansion {
+	var result []Expansion
+	for _, expansion := range expansions {
+		if containsTerm(key, expansion.Synonym) {
+			result = append(result, expansion)
+		}
+	}
+	return result
+}
+
+// containsTerm reports whether the text has the term, not as a part of a
+// longer latin word, "in" is not found in "login".
+func containsTerm(text, term string) bool {
+	if term == "" {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(term)
+	last, _ := utf8.DecodeLastRuneInString(term)
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], term)
+		if index < 0 {
+			return false
+		}
+
+		start := offset + index
+		end := start + len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !(isLatinWord(before) && isLatinWord(first)) && !(isLatinWord(after) && isLatinWord(last)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// isLatinWord reports whether the rune belongs to a word separated by spaces.
+func isLatinWord(r rune) bool {
+	return r <= unicode.MaxLatin1 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainsTerm(t *testing.T) {
+	tests := []struct {
+		text string
+		term string
+		want bool
+	}{
+		{"how do i sign in", "sign in", true},
+		{"sign in failed", "sign in", true},
+		{"how to login", "in", false},
+		{"how to log in", "in", true},
+		{"无法登录怎么办", "登录", true},
+		{"登录sign in", "sign in", true},
+		{"resigning", "sign", false},
+		{"", "sign", false},
+	}
+
+	for _, test := range tests {
+		if got := containsTerm(test.text, test.term); got != test.want {
+			t.Errorf("containsTerm(%q, %q) = %v, want %v", test.text, test.term, got, test.want)
+		}
+	}
+}
+
+func TestSynonyms_Expansions(t *testing.T) {
+	synonyms := NewSynonyms([][]string{
+		{"登录", "登陆", "Sign In"},
+		{"only"},
+		{"wifi", "WiFi"},
+	})
+	if synonyms.Len() != 1 {
+		t.Fatalf("expected the sets without two different terms to be dropped, got %d sets", synonyms.Len())
+	}
+
+	expansions := synonyms.expansions("如何登录")
+	want := []Expansion{{"登录", "登陆"}, {"登录", "sign in"}}
+	if !reflect.DeepEqual(expansions, want) {
+		t.Errorf("expected %v, got %v", want, expansions)
+	}
+
+	if expansions := synonyms.expansions("登录 or sign in"); !reflect.DeepEqual(expansions,
+		[]Expansion{{"登录", "登陆"}, {"sign in", "登陆"}}) {
+		t.Errorf("expected the terms of the text not to be expanded to each other, got %v", expansions)
+	}
+
+	var none *Synonyms
+	if expansions := none.expansions("如何登录"); expansions != nil {
+		t.Errorf("expected no expansion without synonyms, got %v", expansions)
+	}
+}
@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wangbin/jiebago"
+)
+
+const (
+	TokenizerJieba = "jieba"
+	TokenizerWord  = "word"
+	TokenizerNgram = "ngram"
+
+	DefaultNgramSize = 2
+)
+
+type (
+	// Tokenizer segments the keys and the queries of a storage, and picks the
+	// keywords the keys are indexed with.
+	Tokenizer interface {
+		// Cut returns the segments of the text in order, the ones without
+		// letters or digits are dropped by the storages.
+		Cut(string) []string
+		// Keywords returns the most important words of the text, at most
+		// top ones unless the tokenizer keeps them all. Words are weighted
+		// with their frequency in the text and their idf, the stop words
+		// are dropped.
+		Keywords(text string, top int, weigher Weigher) []string
+		// Known reports whether the word is in the dictionary of the
+		// tokenizer, so that it is not a typo.
+		Known(string) bool
+	}
+
+	// jiebaTokenizer segments with a jieba dictionary.
+	jiebaTokenizer struct {
+		segmenter *jiebago.Segmenter
+	}
+
+	// wordTokenizer splits on the word boundaries of the languages delimited
+	// by spaces, the characters of the languages without spaces are words.
+	wordTokenizer struct{}
+
+	// ngramTokenizer splits the runs of characters of the languages without
+	// spaces, like Chinese, Japanese or Thai, into overlapping n-grams. The
+	// other words are split like wordTokenizer.
+	ngramTokenizer struct {
+		size int
+	}
+)
+
+var (
+	defaultJieba     Tokenizer
+	defaultJiebaOnce sync.Once
+
+	// unspacedScripts are written without spaces between words.
+	unspacedScripts = []*unicode.RangeTable{
+		unicode.Han, unicode.Hiragana, unicode.Katakana,
+		unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar,
+	}
+
+	// englishStopWords are the stop words jieba drops by default.
+	englishStopWords = map[string]bool{
+		"the": true, "of": true, "is": true, "and": true, "to": true, "in": true, "that": true,
+		"we": true, "for": true, "an": true, "are": true, "by": true, "be": true, "as": true,
+		"on": true, "with": true, "can": true, "if": true, "from": true, "which": true, "you": true,
+		"it": true, "this": true, "then": true, "at": true, "have": true, "all": true, "not": true,
+		"one": true, "has": true, "or": true,
+	}
+)
+
+// NewTokenizer creates the tokenizer of the name, jieba when it is empty. size
+// is the length of the n-grams, DefaultNgramSize when it is not positive. dict
+// is the dictionary of jieba, the default one when it is nil.
+func NewTokenizer(name string, size int, dict []byte) (Tokenizer, error) {
+	switch name {
+	case "", TokenizerJieba:
+		if dict == nil {
+			return NewJiebaTokenizer(), nil
+		}
+		return LoadJiebaTokenizer(dict)
+	case TokenizerWord:
+		return NewWordTokenizer(), nil
+	case TokenizerNgram:
+		return NewNgramTokenizer(size), nil
+	default:
+		return nil, fmt.Errorf("unknown tokenizer '%s'", name)
+	}
+}
+
+// NewJiebaTokenizer returns the jieba tokenizer of the default dictionary, it
+// is loaded once and shared by the storages.
+func NewJiebaTokenizer() Tokenizer {
+	defaultJiebaOnce.Do(func() {
+		var err error
+		if defaultJieba, err = LoadJiebaTokenizer(DefaultResources().Dict); err != nil {
+			fmt.Printf("Could not load the default jieba dictionary: %s\n", err.Error())
+			defaultJieba = &jiebaTokenizer{segmenter: &jiebago.Segmenter{}}
+		}
+	})
+	return defaultJieba
+}
+
+// LoadJiebaTokenizer creates a jieba tokenizer with the dictionary, see
+// Resources.Dict.
+func LoadJiebaTokenizer(dict []byte) (Tokenizer, error) {
+	if err := ValidateResource(ResourceDict, dict); err != nil {
+		return nil, err
+	}
+
+	// jieba only loads its dictionary from a file
+	fileName, err := writeTempResource(ResourceDict, dict)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(fileName)
+
+	var segmenter jiebago.Segmenter
+	if err := segmenter.LoadDictionary(fileName); err != nil {
+		return nil, err
+	}
+	return &jiebaTokenizer{segmenter: &segmenter}, nil
+}
+
+func NewWordTokenizer() Tokenizer {
+	return wordTokenizer{}
+}
+
+func NewNgramTokenizer(size int) Tokenizer {
+	if size <= 0 {
+		size = DefaultNgramSize
+	}
+	return ngramTokenizer{size: size}
+}
+
+func (tokenizer *jiebaTokenizer) Cut(text string) []string {
+	var words []string
+	for word := range tokenizer.segmenter.Cut(text, true) {
+		words = append(words, word)
+	}
+	return words
+}
+
+// Keywords extracts the tags of the text like the extracter of jieba, the
+// words shorter than two characters are dropped.
+func (tokenizer *jiebaTokenizer) Keywords(text string, top int, weigher Weigher) []string {
+	var words []string
+	frequencies := make(map[string]int)
+	for word := range tokenizer.segmenter.Cut(text, true) {
+		word = strings.TrimSpace(word)
+		if utf8.RuneCountInString(word) < 2 || weigher.IsStopWord(word) {
+			continue
+		}
+		if frequencies[word] == 0 {
+			words = append(words, word)
+		}
+		frequencies[word]++
+	}
+
+	weights := make(map[string]float64, len(words))
+	for _, word := range words {
+		weights[word] = float64(frequencies[word]) * weigher.Idf(word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if weights[words[i]] != weights[words[j]] {
+			return weights[words[i]] > weights[words[j]]
+		}
+		return words[i] > words[j]
+	})
+	if len(words) > top {
+		words = words[:top]
+	}
+	return words
+}
+
+func (tokenizer *jiebaTokenizer) Known(word string) bool {
+	_, ok := tokenizer.segmenter.Frequency(word)
+	return ok
+}
+
+func (wordTokenizer) Cut(text string) []string {
+	return segment(text, 1)
+}
+
+func (wordTokenizer) Keywords(text string, top int, weigher Weigher) []string {
+	keywords := rankWords(segment(text, 1), weigher)
+	if len(keywords) > top {
+		keywords = keywords[:top]
+	}
+	return keywords
+}
+
+func (wordTokenizer) Known(word string) bool {
+	return englishStopWords[word]
+}
+
+func (tokenizer ngramTokenizer) Cut(text string) []string {
+	return segment(text, tokenizer.size)
+}
+
+// Keywords keeps every n-gram, none of them is more important than the others
+// without a dictionary.
+func (tokenizer ngramTokenizer) Keywords(text string, _ int, weigher Weigher) []string {
+	return rankWords(segment(text, tokenizer.size), weigher)
+}
+
+func (ngramTokenizer) Known(word string) bool {
+	return englishStopWords[word]
+}
+
+// segment splits the text into words, separators and the n-grams of the runs
+// of characters written without spaces. A run shorter than size is kept.
+func segment(text string, size int) []string {
+	var segments []string
+	var run []rune
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if len(run) <= size {
+			segments = append(segments, string(run))
+		} else {
+			for i := 0; i+size <= len(run); i++ {
+				segments = append(segments, string(run[i:i+size]))
+			}
+		}
+		run = run[:0]
+	}
+
+	for start := 0; start < len(text); {
+		r, width := utf8.DecodeRuneInString(text[start:])
+		if isUnspaced(r) {
+			run = append(run, r)
+			start += width
+			continue
+		}
+		flush()
+
+		end := start + width
+		if isWordRune(r) {
+			for end < len(text) {
+				next, nextWidth := utf8.DecodeRuneInString(text[end:])
+				if isWordRune(next) && !isUnspaced(next) {
+					end += nextWidth
+				} else if (next == '\'' || next == '’') && end+nextWidth < len(text) {
+					// an apostrophe within a word, like "don't"
+					after, _ := utf8.DecodeRuneInString(text[end+nextWidth:])
+					if !isWordRune(after) || isUnspaced(after) {
+						break
+					}
+					end += nextWidth
+				} else {
+					break
+				}
+			}
+		} else {
+			for end < len(text) {
+				next, nextWidth := utf8.DecodeRuneInString(text[end:])
+				if isWordRune(next) || isUnspaced(next) {
+					break
+				}
+				end += nextWidth
+			}
+		}
+		segments = append(segments, text[start:end])
+		start = end
+	}
+	flush()
+
+	return segments
+}
+
+// rankWords returns the words without the stop words and the single latin
+// letters, by descending frequency times idf, then in their order.
+func rankWords(segments []string, weigher Weigher) []string {
+	var words []string
+	frequencies := make(map[string]int)
+	for _, word := range segments {
+		if !isWord(word) || weigher.IsStopWord(word) ||
+			isLatinText(word) && utf8.RuneCountInString(word) < 2 {
+			continue
+		}
+		if frequencies[word] == 0 {
+			words = append(words, word)
+		}
+		frequencies[word]++
+	}
+
+	weights := make(map[string]float64, len(words))
+	for _, word := range words {
+		weights[word] = float64(frequencies[word]) * weigher.Idf(word)
+	}
+	sort.SliceStable(words, func(i, j int) bool {
+		return weights[words[i]] > weights[words[j]]
+	})
+	return words
+}
+
+func isUnspaced(r rune) bool {
+	return unicode.In(r, unspacedScripts...)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func idfOf(weights map[string]float64) Weigher {
+	idf := newIdfTable()
+	for term, weight := range weights {
+		idf.frequencies[term] = weight
+	}
+	return &vocabulary{idf: idf, stopWords: parseStopWords(nil)}
+}
+
+func TestWordTokenizer_Cut(t *testing.T) {
+	tokenizer := NewWordTokenizer()
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"don't reset it!", []string{"don't", " ", "reset", " ", "it", "!"}},
+		{"wi-fi 5GHz", []string{"wi", "-", "fi", " ", "5GHz"}},
+		{"привет мир", []string{"привет", " ", "мир"}},
+		{"重置wifi密码", []string{"重", "置", "wifi", "密", "码"}},
+	}
+
+	for _, test := range tests {
+		if got := tokenizer.Cut(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Cut(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestNgramTokenizer_Cut(t *testing.T) {
+	if got, want := NewNgramTokenizer(2).Cut("重置wifi密码"), []string{"重置", "wifi", "密码"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the runs to be split in bigrams, got %q", got)
+	}
+	if got, want := NewNgramTokenizer(0).Cut("忘记密码"), []string{"忘记", "记密", "密码"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected overlapping bigrams by default, got %q", got)
+	}
+	if got, want := NewNgramTokenizer(3).Cut("密码 ok"), []string{"密码", " ", "ok"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected a run shorter than the n-grams to be kept, got %q", got)
+	}
+}
+
+func TestWordTokenizer_Keywords(t *testing.T) {
+	keywords := NewWordTokenizer().Keywords("How to reset the password of a laptop", 3,
+		idfOf(map[string]float64{"password": 9, "laptop": 8, "reset": 5}))
+	if want := []string{"password", "laptop", "reset"}; !reflect.DeepEqual(keywords, want) {
+		t.Errorf("expected the rarest words without the stop words, got %q", keywords)
+	}
+
+	keywords = NewNgramTokenizer(2).Keywords("忘记密码", 1, idfOf(nil))
+	if want := []string{"忘记", "记密", "密码"}; !reflect.DeepEqual(keywords, want) {
+		t.Errorf("expected every n-gram to be kept, got %q", keywords)
+	}
+}
+
+func TestNewTokenizer(t *testing.T) {
+	if _, err := NewTokenizer("unknown", 0, nil); err == nil {
+		t.Error("expected an error for an unknown tokenizer")
+	}
+	for _, name := range []string{"", TokenizerJieba, TokenizerWord, TokenizerNgram} {
+		if tokenizer, err := NewTokenizer(name, 0, nil); err != nil || tokenizer == nil {
+			t.Errorf("expected the %q tokenizer, got %v", name, err)
+		}
+	}
+}
+
+func TestMemoryStorage_Tokenizer(t *testing.T) {
+	for _, tokenizer := range []Tokenizer{NewWordTokenizer(), NewNgramTokenizer(2)} {
+		storage := NewMemoryStorage()
+		storage.SetTokenizer(tokenizer)
+		for _, key := range []string{"how do I reset my password", "忘记密码怎么办", "where is the printer"} {
+			storage.Update(key, []Record{{Question: key, Answer: "answer"}})
+		}
+		storage.BuildIndex()
+
+		if keys := storage.Search("PASSWORD reset"); len(keys) != 1 || keys[0] != "how do I reset my password" {
+			t.Errorf("%T: expected the english question, got %v", tokenizer, keys)
+		}
+		if tokens := storage.Tokens("Reset, the printer!"); !reflect.DeepEqual(tokens, []string{"reset", "the", "printer"}) {
+			t.Errorf("%T: expected the words of the text, got %v", tokenizer, tokens)
+		}
+	}
+
+	storage := NewMemoryStorage()
+	storage.SetTokenizer(NewNgramTokenizer(2))
+	storage.Update("忘记密码怎么办", []Record{{Question: "忘记密码怎么办", Answer: "answer"}})
+	storage.BuildIndex()
+	if keys := storage.Search("密码忘了"); len(keys) != 1 {
+		t.Errorf("expected the bigrams to match without a dictionary, got %v", keys)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -16,21 +17,45 @@ import (
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/input"
 	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/output"
 	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+	"github.com/jeffdoubleyou/chatbot/bot/dialogue"
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
+	"github.com/jeffdoubleyou/chatbot/bot/session"
+	"github.com/jeffdoubleyou/chatbot/bot/slot"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 const mega = 1024 * 1024
 
+var defaultInputAdapters = []input.InputAdapter{
+	input.NewJSONInput(),
+	input.NewChannelInput(),
+	input.NewTextInput(),
+}
+
 type ChatBot struct {
 	PrintMemStats bool
-	//InputAdapter   input.InputAdapter
-	LogicAdapter logic.LogicAdapter
-	//OutputAdapter  output.OutputAdapter
+	// InputAdapters read the requests of Respond, the JSON, channel and
+	// text adapters are used when it is nil.
+	InputAdapters []input.InputAdapter
+	LogicAdapter  logic.LogicAdapter
+	// OutputAdapters format the answers and may drop them.
+	OutputAdapters []output.OutputAdapter
 	StorageAdapter storage.StorageAdapter
 	Trainer        Trainer
 	Config         Config
+	// Sessions keeps the conversations of Converse, an in-memory store
+	// expiring after Config.SessionTTL is used when it is nil.
+	Sessions     session.Store
+	sessionsOnce sync.Once
+	// Classifier predicts the class of the questions when Config.Intent is
+	// set, it is trained by TrainWithDB.
+	Classifier *logic.IntentClassifier
+	// rules is the pattern adapter answering before the others
+	rules logic.RuleSetter
 }
 
 type CORPUS_TYPE int
@@ -66,7 +91,7 @@ func (f *ChatBotFactory) Init() {
 		if err != nil {
 			panic(err)
 		}
-		err = engine.Sync2(&Corpus{}, &Project{}, &Feedback{})
+		err = engine.Sync2(&Corpus{}, &Project{}, &Feedback{}, &Rule{}, &Synonym{}, &Resource{}, &StopWord{})
 		if err != nil {
 			fmt.Println(err.Error())
 		}
@@ -83,13 +108,29 @@ func (f *ChatBotFactory) Init() {
 		conf.Project = project.Name
 		fmt.Printf("Loading project '%s'\n", project.Name)
 		if _, ok := f.GetChatBot(project.Name); !ok {
-			store := storage.NewMemoryStorage()
+			store, err := newStorage(conf)
+			if err != nil {
+				fmt.Printf("Could not create storage for project %s: %s\n", project.Name, err.Error())
+				continue
+			}
+			patterns, _ := logic.NewPatternMatch(nil)
+			adapter := logic.NewComboMatch(patterns, newLogicAdapter(conf, store))
+			var classifier *logic.IntentClassifier
+			if conf.Intent {
+				classifier = loadIntentClassifier(store)
+				adapter = logic.NewIntentMatch(adapter, store, classifier, conf.IntentRestrict, conf.IntentMinProbability)
+			}
 			chatbot := &ChatBot{
-				LogicAdapter:   logic.NewClosestMatch(store, 5),
+				InputAdapters:  newInputAdapters(conf.Input),
+				LogicAdapter:   adapter,
+				OutputAdapters: newOutputAdapters(conf.Output),
+				Classifier:     classifier,
+				rules:          patterns.(logic.RuleSetter),
 				PrintMemStats:  f.config.PrintMemStats,
 				Trainer:        NewCorpusTrainer(store),
 				StorageAdapter: store,
 				Config:         conf,
+				Sessions:       session.NewMemoryStore(time.Duration(conf.SessionTTL) * time.Second),
 			}
 			f.AddChatBot(project.Name, chatbot)
 			chatbot.Init()
@@ -118,6 +159,24 @@ func (f *ChatBotFactory) AddChatBot(project string, chatBot *ChatBot) {
 
 }
 
+// RemoveChatBot drops the chat bot of the project and closes its storage, so
+// that its file can be opened again.
+func (f *ChatBotFactory) RemoveChatBot(project string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	chatBot, ok := f.chatBots[project]
+	if !ok {
+		return
+	}
+
+	delete(f.chatBots, project)
+	if closer, ok := chatBot.StorageAdapter.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			fmt.Printf("Could not close the storage of project %s: %s\n", project, err.Error())
+		}
+	}
+}
+
 func (f *ChatBotFactory) ListProject() []Project {
 	var projects []Project
 	var err error
@@ -158,6 +217,64 @@ func (f *ChatBotFactory) GetProject(name string) (bool, error) {
 	}
 }
 
+func (f *ChatBotFactory) GetProjectByName(name string) *Project {
+	project := &Project{
+		Name: name,
+	}
+	if ok, err := engine.Get(project); err != nil {
+		fmt.Printf("Error retrieving project %s: %s\n", name, err.Error())
+		return nil
+	} else if !ok {
+		return nil
+	}
+	return project
+}
+
+// DeleteProject removes the project with its corpora, feedback and the rest of
+// its rows, and drops its chat bot from the factory.
+func (f *ChatBotFactory) DeleteProject(name string) error {
+	session := engine.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if _, err := session.Delete(&Corpus{Project: name}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Delete(&Feedback{Project: name}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Delete(&Rule{Project: name}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Delete(&Synonym{Project: name}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Delete(&Resource{Project: name}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Delete(&StopWord{Project: name}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Delete(&Project{Name: name}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if err := session.Commit(); err != nil {
+		return err
+	}
+
+	f.RemoveChatBot(name)
+	return nil
+}
+
 func (f *ChatBotFactory) GetCorpusById(id int) *Corpus {
 	var corpus []Corpus
 	session := engine.Limit(1, 0).Where("id = ?", id)
@@ -202,7 +319,7 @@ func (chatbot *ChatBot) Init() {
 		panic(err)
 	}
 
-	err = engine.Sync2(&Corpus{}, &Project{}, &Feedback{})
+	err = engine.Sync2(&Corpus{}, &Project{}, &Feedback{}, &Rule{}, &Synonym{}, &Resource{}, &StopWord{})
 	if err != nil {
 		fmt.Println(err.Error())
 	}
@@ -217,6 +334,28 @@ func (chatbot *ChatBot) Init() {
 			}
 		}
 	}
+	if err := chatbot.ReloadRules(); err != nil {
+		fmt.Printf("Could not load the rules of project %s: %s\n", chatbot.Config.Project, err.Error())
+	}
+	if err := chatbot.loadSynonyms(); err != nil {
+		fmt.Printf("Could not load the synonyms of project %s: %s\n", chatbot.Config.Project, err.Error())
+	}
+	if err := chatbot.loadStopWordOverrides(); err != nil {
+		fmt.Printf("Could not load the stop words of project %s: %s\n", chatbot.Config.Project, err.Error())
+	}
+	if persistent, ok := chatbot.StorageAdapter.(storage.Persistent); ok && persistent.Persistent() &&
+		chatbot.StorageAdapter.Count() > 0 {
+		fmt.Printf("Using persisted storage for project %s\n", chatbot.Config.Project)
+		if chatbot.Classifier != nil && chatbot.Classifier.Classes() == 0 {
+			corpuses, err := chatbot.LoadCorpusFromDB()
+			if err != nil {
+				panic(err)
+			}
+			chatbot.trainClassifier(corpuses)
+		}
+		return
+	}
+
 	err = chatbot.TrainWithDB()
 	if err != nil {
 		panic(err)
@@ -235,13 +374,61 @@ type Corpus struct {
 	AcceptCount int        `json:"accept_count" form:"accept_count" xorm:"int notnull default 0  'accept_count' comment('解决次数')"`
 	RejectCount int        `json:"reject_count" form:"reject_count" xorm:"int notnull  default 0 'reject_count' comment('解决次数')"`
 	CreatTime   time.Time  `json:"creat_time" xorm:"creat_time created" json:"creat_time" description:"创建时间"`
-	UpdateTime  time.Time  `json:"update_time" xorm:"update_time updated"json:"update_time"description:"更新时间"`
+	UpdateTime  time.Time  `json:"update_time" xorm:"update_time updated" description:"更新时间"`
 	Qtype       int        `json:"qtype" form:"qtype" xorm:"int notnull 'qtype' comment('类型，需求，问答')"`
 	Context     string     `json:"context" form:"context" xorm:"varchar(255) notnull default '' 'context' comment('Context after answer')"`
 	Contextual  bool       `json:"contextual" form:"contextual" xorm:"int(1) not null default 0 'contextual' comment('Is this conversation contextual')"`
+	State       string     `json:"state" form:"state" xorm:"varchar(255) notnull default '' 'state' comment('Dialogue state answering the question')"`
 	Data        CorpusData `json:"data" form:"data" xorm:"text notnull default '' 'data' comment('Data')"`
 }
 
+var questionSeparator = regexp.MustCompile(`[|｜\r\n]+`)
+
+// Questions splits the corpus question into the questions it answers.
+func (corpus *Corpus) Questions() []string {
+	var questions []string
+	for _, question := range questionSeparator.Split(corpus.Question, -1) {
+		if strings.TrimSpace(question) == "" {
+			continue
+		}
+		if !strings.HasSuffix(question, "?") && !strings.HasSuffix(question, "？") {
+			question = question + "?"
+		}
+		questions = append(questions, question)
+	}
+	return questions
+}
+
+// Records builds the storage records answering each of the corpus questions.
+func (corpus *Corpus) Records() []storage.Record {
+	var records []storage.Record
+	for _, question := range corpus.Questions() {
+		records = append(records, storage.Record{
+			Question:   question,
+			Answer:     corpus.Answer,
+			CorpusId:   corpus.Id,
+			Context:    corpus.Context,
+			Contextual: corpus.Contextual,
+			State:      corpus.State,
+			Class:      corpus.Class,
+			Data:       corpus.Data.Data,
+		})
+	}
+	return records
+}
+
+// Validate checks the answer template and the slots declared in the data of
+// the corpus.
+func (corpus *Corpus) Validate() error {
+	if _, err := parseAnswer(corpus.Answer); err != nil {
+		return fmt.Errorf("corpus '%s': invalid answer template: %s", corpus.Question, err.Error())
+	}
+	if _, err := slot.Parse(corpus.Data.Data); err != nil {
+		return fmt.Errorf("corpus '%s': %s", corpus.Question, err.Error())
+	}
+	return nil
+}
+
 type CorpusData struct {
 	Data map[string]interface{}
 }
@@ -277,7 +464,7 @@ type Feedback struct {
 	AcceptCount int       `json:"accept_count" form:"accept_count" xorm:"int notnull default 0  'accept_count' comment('解决次数')"`
 	RejectCount int       `json:"reject_count" form:"reject_count" xorm:"int notnull default 0  'reject_count' comment('解决次数')"`
 	CreatTime   time.Time `json:"creat_time" xorm:"creat_time created" json:"creat_time" description:"创建时间"`
-	UpdateTime  time.Time `json:"update_time" xorm:"update_time updated"json:"update_time"description:"更新时间"`
+	UpdateTime  time.Time `json:"update_time" xorm:"update_time updated" description:"更新时间"`
 	Qtype       int       `json:"qtype" form:"qtype" xorm:"int notnull 'qtype' comment('类型，需求，问答')"`
 }
 
@@ -289,12 +476,296 @@ type Project struct {
 }
 
 type Config struct {
-	Driver        string `json:"driver"`
-	DataSource    string `json:"data_source"`
-	Project       string `json:"project"`
-	DirCorpus     string `json:"dir_corpus"`
-	StoreFile     string `json:"store_file"`
-	PrintMemStats bool   `json:"print_mem_stats"`
+	Driver        string              `json:"driver"`
+	DataSource    string              `json:"data_source"`
+	Project       string              `json:"project"`
+	DirCorpus     string              `json:"dir_corpus"`
+	StoreFile     string              `json:"store_file"`
+	Storage       string              `json:"storage"`
+	Logic         string              `json:"logic"`
+	BM25K1        float64             `json:"bm25_k1"`
+	BM25B         float64             `json:"bm25_b"`
+	HybridWeights logic.HybridWeights `json:"hybrid_weights"`
+	// Adapters combines logic adapters instead of Logic, the first one able
+	// to process a question answers unless Combo is "merge".
+	Adapters []AdapterConfig `json:"adapters"`
+	Combo    string          `json:"combo"`
+	// MinConfidence drops the answers below it, MaxResults limits the
+	// number of answers when it is positive.
+	MinConfidence float32 `json:"min_confidence"`
+	MaxResults    int     `json:"max_results"`
+	// Language is used when the language of a request has no default
+	// response, the responses are keyed by language like "en" or "zh-TW".
+	Language               string            `json:"language"`
+	NoMatchResponses       map[string]string `json:"no_match_responses"`
+	LowConfidenceResponses map[string]string `json:"low_confidence_responses"`
+	// Intent classifies the questions with a model trained from the classes
+	// of the corpora, IntentRestrict keeps the answers of the predicted class
+	// when its probability reaches IntentMinProbability.
+	Intent               bool    `json:"intent"`
+	IntentRestrict       bool    `json:"intent_restrict"`
+	IntentMinProbability float64 `json:"intent_min_probability"`
+	// RulesFile is a YAML file of pattern rules, used with the rules of the
+	// project in the DB.
+	RulesFile string `json:"rules_file"`
+	// Input and Output name the input and output adapters of the project, in
+	// the order they run.
+	Input  []string `json:"input"`
+	Output []string `json:"output"`
+	// Normalizers are the steps normalizing the questions when they are
+	// trained and asked, like "width", "nfkc", "simplified", "lower",
+	// "punctuation", "space" and "stem". The project must be trained again
+	// when they change.
+	Normalizers []string `json:"normalizers"`
+	// Tokenizer segments the questions, "jieba" by default, "word" for the
+	// languages delimited by spaces or "ngram" for the languages without
+	// dictionaries, with n-grams of NgramSize characters. The project must be
+	// trained again when it changes.
+	Tokenizer string `json:"tokenizer"`
+	NgramSize int    `json:"ngram_size"`
+	// DictFile, IdfFile and StopWordsFile are the jieba dictionary, the idf
+	// table and the stop words of the project. The resources of the project
+	// in the DB replace them, dict.txt, idf.txt and stop_words.txt of the
+	// working directory are used when they are empty.
+	DictFile      string `json:"dict_file"`
+	IdfFile       string `json:"idf_file"`
+	StopWordsFile string `json:"stop_words_file"`
+	// Idf weights the keywords with the idf file when it is "stock" or empty,
+	// with the idf computed from the questions when the index is built when
+	// it is "corpus", or with both when it is "blend", IdfWeight being the
+	// share of the computed idf.
+	Idf       string  `json:"idf"`
+	IdfWeight float64 `json:"idf_weight"`
+	// IndexSynonyms indexes the questions with the synonyms of their terms,
+	// the synonyms always expand the terms of the queries.
+	IndexSynonyms bool `json:"index_synonyms"`
+	// SpellCheck asks again with the misspelled words of a question
+	// corrected, when the question has no answer.
+	SpellCheck bool `json:"spell_check"`
+	// Timezone is the IANA location of the time adapter, the local time
+	// when it is empty.
+	Timezone string `json:"timezone"`
+	// SessionTTL is the number of seconds a conversation is remembered.
+	SessionTTL    int  `json:"session_ttl"`
+	PrintMemStats bool `json:"print_mem_stats"`
+}
+
+const (
+	STORAGE_MEMORY = "memory"
+	STORAGE_SQL    = "sql"
+)
+
+const (
+	LOGIC_CLOSEST = "closest"
+	LOGIC_BM25    = "bm25"
+	LOGIC_HYBRID  = "hybrid"
+	LOGIC_MATH    = "math"
+	LOGIC_TIME    = "time"
+	LOGIC_UNIT    = "unit"
+)
+
+const (
+	COMBO_FIRST = "first"
+	COMBO_MERGE = "merge"
+)
+
+const (
+	ADAPTER_TEXT     = "text"
+	ADAPTER_JSON     = "json"
+	ADAPTER_CHANNEL  = "channel"
+	ADAPTER_MARKDOWN = "markdown"
+)
+
+const defaultTops = 5
+
+// AdapterConfig is a logic adapter of a combination, Timeout is in
+// milliseconds and only applies when the answers are merged.
+type AdapterConfig struct {
+	Logic   string  `json:"logic"`
+	Weight  float32 `json:"weight"`
+	Timeout int     `json:"timeout"`
+}
+
+// newLogicAdapter creates the logic adapter selected by the project config,
+// or combines the adapters of Config.Adapters.
+func newLogicAdapter(conf Config, store storage.StorageAdapter) logic.LogicAdapter {
+	if len(conf.Adapters) == 0 {
+		return newSingleLogicAdapter(conf, conf.Logic, store)
+	}
+
+	if conf.Combo == COMBO_MERGE {
+		var adapters []logic.WeightedAdapter
+		for _, each := range conf.Adapters {
+			adapters = append(adapters, logic.WeightedAdapter{
+				Adapter: newSingleLogicAdapter(conf, each.Logic, store),
+				Weight:  each.Weight,
+				Timeout: time.Duration(each.Timeout) * time.Millisecond,
+			})
+		}
+		return logic.NewMergeMatch(defaultTops, adapters...)
+	}
+
+	var adapters []logic.LogicAdapter
+	for _, each := range conf.Adapters {
+		adapters = append(adapters, newSingleLogicAdapter(conf, each.Logic, store))
+	}
+	return logic.NewComboMatch(adapters...)
+}
+
+func newSingleLogicAdapter(conf Config, name string, store storage.StorageAdapter) logic.LogicAdapter {
+	switch name {
+	case LOGIC_BM25:
+		return logic.NewBM25Match(store, defaultTops, conf.BM25K1, conf.BM25B)
+	case LOGIC_HYBRID:
+		return logic.NewHybridMatch(store, defaultTops, conf.HybridWeights, conf.BM25K1, conf.BM25B, corpusFeedback)
+	case LOGIC_MATH:
+		return logic.NewMathMatch()
+	case LOGIC_TIME:
+		return logic.NewTimeMatch(conf.location())
+	case LOGIC_UNIT:
+		return logic.NewUnitMatch()
+	default:
+		return logic.NewClosestMatch(store, defaultTops)
+	}
+}
+
+// newInputAdapters creates the input adapters named by the project config,
+// nil when there is none so that the default ones are used.
+func newInputAdapters(names []string) []input.InputAdapter {
+	var adapters []input.InputAdapter
+	for _, name := range names {
+		switch name {
+		case ADAPTER_TEXT:
+			adapters = append(adapters, input.NewTextInput())
+		case ADAPTER_JSON:
+			adapters = append(adapters, input.NewJSONInput())
+		case ADAPTER_CHANNEL:
+			adapters = append(adapters, input.NewChannelInput())
+		default:
+			fmt.Printf("Unknown input adapter %s\n", name)
+		}
+	}
+	return adapters
+}
+
+// newOutputAdapters creates the output adapters named by the project config.
+func newOutputAdapters(names []string) []output.OutputAdapter {
+	var adapters []output.OutputAdapter
+	for _, name := range names {
+		switch name {
+		case ADAPTER_TEXT:
+			adapters = append(adapters, output.NewTextOutput())
+		case ADAPTER_JSON:
+			adapters = append(adapters, output.NewJSONOutput())
+		case ADAPTER_MARKDOWN:
+			adapters = append(adapters, output.NewMarkdownOutput())
+		default:
+			fmt.Printf("Unknown output adapter %s\n", name)
+		}
+	}
+	return adapters
+}
+
+// location returns the time zone of the project, or the local one when it is
+// not set or unknown.
+func (conf Config) location() *time.Location {
+	if conf.Timezone == "" {
+		return time.Local
+	}
+
+	location, err := time.LoadLocation(conf.Timezone)
+	if err != nil {
+		fmt.Printf("Unknown timezone %s: %s\n", conf.Timezone, err.Error())
+		return time.Local
+	}
+	return location
+}
+
+// loadIntentClassifier restores the intent classifier saved in the storage, or
+// returns an empty one.
+func loadIntentClassifier(store storage.StorageAdapter) *logic.IntentClassifier {
+	classifier := logic.NewIntentClassifier()
+	models, ok := store.(storage.ModelStore)
+	if !ok {
+		return classifier
+	}
+
+	data, ok, err := models.LoadModel(logic.IntentModelName)
+	if err == nil && ok {
+		err = classifier.UnmarshalBinary(data)
+	}
+	if err != nil {
+		fmt.Printf("Could not load the intent classifier: %s\n", err.Error())
+	}
+	return classifier
+}
+
+// corpusFeedback reads the counters of a corpus, they change with every
+// feedback so they are not kept in the storage.
+func corpusFeedback(id int) (int, int) {
+	if engine == nil {
+		return 0, 0
+	}
+	corpus := Corpus{Id: id}
+	if ok, err := engine.Cols("accept_count", "reject_count").Get(&corpus); err != nil || !ok {
+		return 0, 0
+	}
+	return corpus.AcceptCount, corpus.RejectCount
+}
+
+// newStorage opens the storage of the project, normalizing and segmenting its
+// keys with the normalizers, the tokenizer and the resources of the project.
+func newStorage(conf Config) (storage.StorageAdapter, error) {
+	normalizer, err := nlp.NewNormalizer(conf.Normalizers)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := loadResources(conf)
+	if err != nil {
+		return nil, err
+	}
+	tokenizer, err := storage.NewTokenizer(conf.Tokenizer, conf.NgramSize, resources.Dict)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := openStorage(conf)
+	if err != nil {
+		return nil, err
+	}
+	if normalizing, ok := store.(storage.Normalizing); ok && normalizer != nil {
+		normalizing.SetNormalizer(normalizer)
+	}
+	if tokenizing, ok := store.(storage.Tokenizing); ok {
+		tokenizing.SetTokenizer(tokenizer)
+		if err := tokenizing.SetResources(resources); err != nil {
+			return nil, err
+		}
+	}
+	if computing, ok := store.(storage.IdfComputing); ok {
+		options := storage.IdfOptions{Mode: conf.Idf, Weight: conf.IdfWeight}
+		if err := computing.SetIdfOptions(options); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// openStorage creates the storage adapter selected by the project config, the
+// sql storage shares the engine of the factory and the store file is opened
+// according to its extension.
+func openStorage(conf Config) (storage.StorageAdapter, error) {
+	switch conf.Storage {
+	case "", STORAGE_MEMORY:
+		if conf.StoreFile != "" {
+			return storage.Open(conf.StoreFile)
+		}
+		return storage.NewMemoryStorage(), nil
+	case STORAGE_SQL:
+		return storage.NewSqlStorage(engine, conf.Project)
+	default:
+		return nil, fmt.Errorf("unknown storage '%s'", conf.Storage)
+	}
 }
 
 func (chatbot *ChatBot) Train(data interface{}) error {
@@ -324,8 +795,8 @@ func (chatbot *ChatBot) Train(data interface{}) error {
 	}
 }
 
-func (chatbot *ChatBot) LoadCorpusFromDB() (map[string][][]string, error) {
-	results := make(map[string][][]string)
+func (chatbot *ChatBot) LoadCorpusFromDB() (map[string][]storage.Record, error) {
+	results := make(map[string][]storage.Record)
 	var rows []Corpus
 	query := Corpus{
 		Project: chatbot.Config.Project,
@@ -335,31 +806,25 @@ func (chatbot *ChatBot) LoadCorpusFromDB() (map[string][][]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	var corpuses [][]string
-	exp, err := regexp.Compile(`[|｜\r\n]+`)
-	if err != nil {
-		return nil, err
-	}
+	var records []storage.Record
 	for _, row := range rows {
-		var corpus []string
-		questions := exp.Split(row.Question, -1)
-
-		for _, question := range questions {
-			if strings.TrimSpace(question) == "" {
-				continue
-			}
-			if !strings.HasSuffix(question, "?") && !strings.HasSuffix(question, "？") {
-				question = question + "?"
-			}
-			corpus = append(corpus, question, fmt.Sprintf("%s$$$$%s$$$$%v$$$$%s", question, row.Answer, row.Id, row.Context))
-		}
-		corpuses = append(corpuses, corpus)
+		records = append(records, row.Records()...)
 	}
-	results[chatbot.Config.Project] = corpuses
+	results[chatbot.Config.Project] = records
 	return results, nil
 
 }
 
+// DialogueGraph builds the dialogue states declared by the corpora of the
+// project, Validate reports the unreachable and dead-end ones.
+func (chatbot *ChatBot) DialogueGraph() (*dialogue.Graph, error) {
+	corpora, err := chatbot.LoadCorpusFromDB()
+	if err != nil {
+		return nil, err
+	}
+	return dialogue.NewGraph(corpora[chatbot.Config.Project]), nil
+}
+
 func (chatbot *ChatBot) LoadCorpusFromFiles(filePaths []string) (map[string][][]string, error) {
 	return corpus.LoadCorpora(filePaths)
 }
@@ -382,25 +847,26 @@ func (chatbot *ChatBot) SaveCorpusToDB(corpuses map[string][][]string) {
 
 }
 
+// AddFeedbackToDB saves the feedback with the class of its corpus, in the
+// project given by the caller, else in the one of its corpus or of the chat bot.
 func (chatbot *ChatBot) AddFeedbackToDB(feedback *Feedback) error {
-	corpus := Corpus{
-		Id: feedback.Cid,
+	if feedback.Cid > 0 {
+		corpus := Corpus{
+			Id: feedback.Cid,
+		}
+		if ok, _ := engine.Get(&corpus); ok {
+			if feedback.Project == "" {
+				feedback.Project = corpus.Project
+			}
+			feedback.Class = corpus.Class
+		}
 	}
-	var (
-		ok  bool
-		err error
-	)
-	if ok, _ = engine.Get(&corpus); ok {
-		feedback.Project = corpus.Project
-		feedback.Class = corpus.Class
-		_, err = engine.Insert(feedback)
-		return err
-	} else {
-		_, err = engine.Insert(feedback)
+	if feedback.Project == "" {
+		feedback.Project = chatbot.Config.Project
 	}
 
+	_, err := engine.Insert(feedback)
 	return err
-
 }
 
 func (chatbot *ChatBotFactory) UpdateCorpusCounter(id int, isOk bool) error {
@@ -431,6 +897,9 @@ func (chatbot *ChatBotFactory) UpdateCorpusCounter(id int, isOk bool) error {
 }
 
 func (chatbot *ChatBot) AddCorpusToDB(corpus *Corpus) error {
+	if err := corpus.Validate(); err != nil {
+		return err
+	}
 	q := Corpus{
 		Question: corpus.Question,
 		Class:    corpus.Class,
@@ -465,13 +934,65 @@ func (chatbot *ChatBot) RemoveCorpusFromDB(corpus *Corpus) error {
 		q.Question = corpus.Question
 	}
 	if ok, err := engine.Get(&q); ok {
-		chatbot.StorageAdapter.Remove(q.Question)
-		engine.Delete(&q)
+		chatbot.RemoveCorpusFromStorage(&q)
+		_, err = engine.Delete(&q)
+		return err
+	} else {
+		return err
+	}
+}
+
+// UpdateCorpusInDB saves every column of corpus and replaces the answers of
+// previous in the storage with the updated ones.
+func (chatbot *ChatBot) UpdateCorpusInDB(previous, corpus *Corpus) error {
+	if corpus.Id <= 0 {
+		return errors.New("id must be set value")
+	}
+	if err := corpus.Validate(); err != nil {
+		return err
+	}
+	if _, err := engine.Id(corpus.Id).AllCols().Update(corpus); err != nil {
 		return err
 	}
+
+	chatbot.RemoveCorpusFromStorage(previous)
+	if corpus.Qtype == int(CORPUS_CORPUS) {
+		chatbot.AddCorpusToStorage(corpus)
+	}
 	return nil
 }
 
+// AddCorpusToStorage adds the answers of the corpus to the storage.
+func (chatbot *ChatBot) AddCorpusToStorage(corpus *Corpus) {
+	trainer := NewConversationTrainer(chatbot.StorageAdapter)
+	for _, record := range corpus.Records() {
+		trainer.Learn(record)
+	}
+}
+
+// RemoveCorpusFromStorage removes the answers of the corpus from the storage.
+func (chatbot *ChatBot) RemoveCorpusFromStorage(corpus *Corpus) {
+	for _, question := range corpus.Questions() {
+		records, ok := chatbot.StorageAdapter.Find(question)
+		if !ok {
+			continue
+		}
+
+		var remains []storage.Record
+		for _, record := range records {
+			if record.CorpusId != corpus.Id {
+				remains = append(remains, record)
+			}
+		}
+
+		if len(remains) > 0 {
+			chatbot.StorageAdapter.Update(question, remains)
+		} else {
+			chatbot.StorageAdapter.Remove(question)
+		}
+	}
+}
+
 func (chatbot *ChatBot) TrainWithDB() error {
 	start := time.Now()
 	defer func() {
@@ -498,12 +1019,64 @@ func (chatbot *ChatBot) TrainWithDB() error {
 	if err := chatbot.Trainer.TrainWithCorpus(corpuses); err != nil {
 		return err
 	} else {
+		chatbot.trainClassifier(corpuses)
 		return nil
 		//return chatbot.StorageAdapter.Sync()
 	}
 
 }
 
+// trainClassifier trains the intent classifier with the classes of the
+// corpora and saves it in the storage.
+func (chatbot *ChatBot) trainClassifier(corpuses map[string][]storage.Record) {
+	if chatbot.Classifier == nil {
+		return
+	}
+
+	analyzer, ok := chatbot.StorageAdapter.(storage.Analyzer)
+	var samples []logic.IntentSample
+	for _, records := range corpuses {
+		for _, record := range records {
+			tokens := strings.Fields(strings.ToLower(record.Question))
+			if ok {
+				tokens = analyzer.Tokens(record.Question)
+			}
+			samples = append(samples, logic.IntentSample{Class: record.Class, Tokens: tokens})
+		}
+	}
+	chatbot.Classifier.Train(samples)
+
+	models, ok := chatbot.StorageAdapter.(storage.ModelStore)
+	if !ok {
+		return
+	}
+	data, err := chatbot.Classifier.MarshalBinary()
+	if err == nil {
+		err = models.SaveModel(logic.IntentModelName, data)
+	}
+	if err != nil {
+		fmt.Printf("Could not save the intent classifier of project %s: %s\n", chatbot.Config.Project, err.Error())
+	}
+}
+
+// Classify predicts the class of the question, when the logic adapter
+// classifies questions.
+func (chatbot *ChatBot) Classify(text string) (string, float64) {
+	if classifier, ok := chatbot.LogicAdapter.(logic.Classifier); ok {
+		return classifier.Classify(text)
+	}
+	return "", 0
+}
+
+// Explain scores the candidates of the question with each signal, false when
+// the logic adapter doesn't explain its scores.
+func (chatbot *ChatBot) Explain(text string, context ...string) ([]logic.Explanation, bool) {
+	if explaining, ok := chatbot.LogicAdapter.(logic.Explaining); ok {
+		return explaining.Explain(text, context...)
+	}
+	return nil, false
+}
+
 func (chatbot *ChatBot) FindCorporaFiles(dir string) []string {
 	var files []string
 
@@ -532,9 +1105,287 @@ func (chatbot *ChatBot) FindCorporaFiles(dir string) []string {
 	return append(files, yamlFiles...)
 }
 
+// GetResponse returns the answers of the logic adapter, applying the minimum
+// confidence and the maximum number of results of the project.
 func (chatbot *ChatBot) GetResponse(text string, context ...string) []logic.Answer {
-	if chatbot.LogicAdapter.CanProcess(text) {
-		return chatbot.LogicAdapter.Process(text, context...)
+	answers, _ := chatbot.getResponse(text, context...)
+	return answers
+}
+
+// Reply returns the answers of GetResponse, or the default response in the
+// preferred languages when there is none. languages may be an Accept-Language
+// header.
+func (chatbot *ChatBot) Reply(text, languages string, context ...string) ([]logic.Answer, string) {
+	answers, fallback := chatbot.reply(text, languages, context...)
+	answers = chatbot.format(renderAnswers(answers, answerValues{Input: text}))
+	if len(answers) == 0 && fallback == "" {
+		fallback = chatbot.NoMatchResponse(languages)
 	}
-	return nil
+	return answers, fallback
+}
+
+// Respond reads a raw request with the input adapters, like a text, a JSON
+// message or the payload of a channel, and replies to it within its session
+// like Converse, or like Reply when it has none. The language of the request
+// comes before the given ones.
+func (chatbot *ChatBot) Respond(request interface{}, languages string) (input.Statement, Turn, error) {
+	adapters := chatbot.InputAdapters
+	if adapters == nil {
+		adapters = defaultInputAdapters
+	}
+	statement, err := input.Pipeline(request, adapters...)
+	if err != nil {
+		return statement, Turn{}, err
+	}
+
+	if statement.Language != "" {
+		languages = strings.TrimSuffix(statement.Language+","+languages, ",")
+	}
+	var context []string
+	if statement.Context != "" {
+		context = []string{statement.Context}
+	}
+
+	if statement.Session != "" {
+		return statement, chatbot.Converse(statement.Session, statement.Text, languages, context...), nil
+	}
+
+	answers, fallback := chatbot.Reply(statement.Text, languages, context...)
+	return statement, Turn{Answers: answers, Fallback: fallback}, nil
+}
+
+// format runs the output adapters on the answers, dropping the ones they
+// reject.
+func (chatbot *ChatBot) format(answers []logic.Answer) []logic.Answer {
+	if len(chatbot.OutputAdapters) == 0 {
+		return answers
+	}
+
+	formatted := make([]logic.Answer, 0, len(answers))
+	for _, answer := range answers {
+		content, ok := output.Pipeline(answer.Content, answer.Confidence, chatbot.OutputAdapters...)
+		if !ok {
+			continue
+		}
+		answer.Content = content
+		formatted = append(formatted, answer)
+	}
+	return formatted
+}
+
+// reply is Reply without rendering the answers.
+func (chatbot *ChatBot) reply(text, languages string, context ...string) ([]logic.Answer, string) {
+	answers, lowConfidence := chatbot.getResponse(text, context...)
+	if len(answers) > 0 {
+		return answers, ""
+	}
+	if lowConfidence {
+		return nil, chatbot.LowConfidenceResponse(languages)
+	}
+	return nil, chatbot.NoMatchResponse(languages)
+}
+
+// Turn is the reply of Converse, either answers, a prompt for a missing slot
+// of the answer or the default response when there is no answer.
+type Turn struct {
+	Answers  []logic.Answer    `json:"answers"`
+	Fallback string            `json:"fallback,omitempty"`
+	Prompt   string            `json:"prompt,omitempty"`
+	Slots    map[string]string `json:"slots,omitempty"`
+	Context  string            `json:"context,omitempty"`
+}
+
+// Converse replies within the session like a dialogue: the answers of the
+// state the session is in come first, then the global answers, that is the
+// ones without a state. A context given by the caller replaces the state.
+// The session moves to the context of a contextual answer and back to the
+// global state otherwise. An answer declaring slots is given once its
+// required slots are filled, the session prompts for them in turn and keeps
+// them in its variables. The answers are rendered with the session.
+func (chatbot *ChatBot) Converse(sessionId, text, languages string, context ...string) Turn {
+	store := chatbot.sessions()
+	conversation, ok := store.Get(sessionId)
+	if !ok {
+		conversation = session.New(sessionId)
+	}
+	if conversation.Vars == nil {
+		conversation.Vars = make(map[string]interface{})
+	}
+
+	turn := chatbot.turn(conversation, text, languages, context...)
+	turn.Answers = chatbot.format(renderAnswers(turn.Answers, answerValues{
+		Input: text,
+		Vars:  conversation.Vars,
+		Slots: turn.Slots,
+	}))
+	if len(turn.Answers) == 0 && turn.Fallback == "" && turn.Prompt == "" {
+		turn.Fallback = chatbot.NoMatchResponse(languages)
+	}
+	store.Save(conversation)
+	return turn
+}
+
+func (chatbot *ChatBot) turn(conversation *session.Session, text, languages string, context ...string) Turn {
+	if conversation.Pending != nil {
+		slots, _ := slot.Parse(conversation.Pending.Data)
+		if slots.Fill(text, conversation.Prompted, conversation.Slots) > 0 {
+			return chatbot.fillSlots(conversation, slots, nil)
+		}
+	}
+
+	answers, fallback := chatbot.converse(conversation, text, languages, context...)
+	if len(answers) == 0 {
+		if conversation.Pending != nil {
+			slots, _ := slot.Parse(conversation.Pending.Data)
+			return chatbot.fillSlots(conversation, slots, nil)
+		}
+		return Turn{Fallback: fallback, Context: conversation.Context}
+	}
+
+	record := answers[0].Record
+	slots, err := slot.Parse(record.Data)
+	if err != nil {
+		fmt.Printf("Could not parse the slots of corpus %d: %s\n", record.CorpusId, err.Error())
+	}
+	if len(slots) > 0 {
+		conversation.Pending = &record
+		conversation.Slots = make(map[string]string)
+		slots.Fill(text, "", conversation.Slots)
+		return chatbot.fillSlots(conversation, slots, answers)
+	}
+
+	conversation.Pending = nil
+	conversation.Prompted = ""
+	conversation.Slots = nil
+	enter(conversation, record)
+	return Turn{Answers: answers, Context: conversation.Context}
+}
+
+// converse looks for the answers in the state of the session, then for the
+// global ones.
+func (chatbot *ChatBot) converse(conversation *session.Session, text, languages string,
+	context ...string) ([]logic.Answer, string) {
+	if len(context) > 0 {
+		return chatbot.reply(text, languages, context...)
+	}
+
+	var answers []logic.Answer
+	var fallback string
+	if conversation.Context != "" {
+		answers, fallback = chatbot.reply(text, languages, conversation.Context)
+	}
+	if len(answers) == 0 {
+		answers, fallback = chatbot.reply(text, languages)
+		answers = globalAnswers(answers)
+		if len(answers) == 0 && fallback == "" {
+			fallback = chatbot.NoMatchResponse(languages)
+		}
+	}
+	return answers, fallback
+}
+
+// fillSlots prompts for the first missing slot of the pending answer, or gives
+// it with the filled slots.
+func (chatbot *ChatBot) fillSlots(conversation *session.Session, slots slot.Slots, answers []logic.Answer) Turn {
+	filled := conversation.Slots
+	if missing, ok := slots.Missing(filled); ok {
+		conversation.Prompted = missing.Name
+		prompt := missing.Prompt
+		if prompt == "" {
+			prompt = missing.Name
+		}
+		return Turn{Prompt: prompt, Slots: filled, Context: conversation.Context}
+	}
+
+	record := *conversation.Pending
+	if len(answers) == 0 {
+		answers = []logic.Answer{{Content: record.Answer, Confidence: 1, Record: record}}
+	}
+	for name, value := range filled {
+		conversation.Vars[name] = value
+	}
+	conversation.Pending = nil
+	conversation.Prompted = ""
+	conversation.Slots = nil
+	enter(conversation, record)
+	return Turn{Answers: answers, Slots: filled, Context: conversation.Context}
+}
+
+// enter moves the session to the context of a contextual answer, or back to
+// the global state.
+func enter(conversation *session.Session, record storage.Record) {
+	conversation.Context = ""
+	if record.Contextual {
+		conversation.Context = record.Context
+	}
+}
+
+// globalAnswers drops the follow-ups of dialogue states.
+func globalAnswers(answers []logic.Answer) []logic.Answer {
+	global := answers[:0]
+	for _, answer := range answers {
+		if answer.Record.State == "" {
+			global = append(global, answer)
+		}
+	}
+	return global
+}
+
+func (chatbot *ChatBot) sessions() session.Store {
+	chatbot.sessionsOnce.Do(func() {
+		if chatbot.Sessions == nil {
+			chatbot.Sessions = session.NewMemoryStore(time.Duration(chatbot.Config.SessionTTL) * time.Second)
+		}
+	})
+	return chatbot.Sessions
+}
+
+// getResponse also tells if answers were dropped for their confidence. A
+// question without answers is asked again corrected when Config.SpellCheck is
+// set, the answers keep the correction.
+func (chatbot *ChatBot) getResponse(text string, context ...string) ([]logic.Answer, bool) {
+	answers, lowConfidence := chatbot.process(text, context...)
+	if len(answers) > 0 || !chatbot.Config.SpellCheck {
+		return answers, lowConfidence
+	}
+
+	corrector, ok := chatbot.StorageAdapter.(storage.Correcting)
+	if !ok {
+		return answers, lowConfidence
+	}
+	corrected, ok := corrector.Correct(text)
+	if !ok {
+		return answers, lowConfidence
+	}
+
+	retried, _ := chatbot.process(corrected, context...)
+	if len(retried) == 0 {
+		return answers, lowConfidence
+	}
+	for i := range retried {
+		retried[i].Correction = corrected
+	}
+	return retried, false
+}
+
+// process returns the answers of the logic adapter above the minimum
+// confidence.
+func (chatbot *ChatBot) process(text string, context ...string) ([]logic.Answer, bool) {
+	if !chatbot.LogicAdapter.CanProcess(text) {
+		return nil, false
+	}
+
+	var answers []logic.Answer
+	var lowConfidence bool
+	for _, answer := range chatbot.LogicAdapter.Process(text, context...) {
+		if chatbot.Config.MaxResults > 0 && len(answers) >= chatbot.Config.MaxResults {
+			break
+		}
+		if answer.Confidence < chatbot.Config.MinConfidence {
+			lowConfidence = true
+			continue
+		}
+		answers = append(answers, answer)
+	}
+	return answers, lowConfidence
 }
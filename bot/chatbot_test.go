@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/input"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+func TestChatBot_Converse(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	for i, record := range []storage.Record{
+		{Question: "i want to book a room?", Answer: "for which date?", Context: "booking", Contextual: true},
+		{Question: "tomorrow?", Answer: "booked for tomorrow", Context: "booking"},
+		{Question: "tomorrow?", Answer: "tomorrow is sunny", Context: "weather"},
+		{Question: "how is the weather?", Answer: "for which day?", Context: "weather", Contextual: true},
+	} {
+		record.CorpusId = i + 1
+		record.Occurrence = 1
+		records, _ := store.Find(record.Question)
+		store.Update(record.Question, append(records, record))
+	}
+	store.BuildIndex()
+
+	chatbot := &ChatBot{LogicAdapter: logic.NewClosestMatch(store, 1)}
+	turns := []struct {
+		session  string
+		question string
+		answer   string
+	}{
+		{"alice", "i want to book a room?", "for which date?"},
+		{"bob", "how is the weather?", "for which day?"},
+		{"alice", "tomorrow?", "booked for tomorrow"},
+		{"bob", "tomorrow?", "tomorrow is sunny"},
+	}
+	for _, turn := range turns {
+		answers := chatbot.Converse(turn.session, turn.question, "").Answers
+		if len(answers) == 0 || answers[0].Content != turn.answer {
+			t.Errorf("%s asked '%s', expected '%s', got %v", turn.session, turn.question, turn.answer, answers)
+		}
+	}
+
+	// the last answers are not contextual, they end the flows
+	if conversation, ok := chatbot.Sessions.Get("alice"); !ok || conversation.Context != "" {
+		t.Errorf("expected alice to leave the booking context, got %v", conversation)
+	}
+}
+
+func TestChatBot_ConverseStates(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	for i, record := range []storage.Record{
+		{Question: "i want to book a room?", Answer: "for which date?", Context: "date", Contextual: true},
+		{Question: "tomorrow?", Answer: "smoking or not?", State: "date", Context: "smoking", Contextual: true},
+		{Question: "no smoking?", Answer: "booked", State: "smoking"},
+		{Question: "what is the weather for tomorrow?", Answer: "sunny"},
+	} {
+		record.CorpusId = i + 1
+		record.Occurrence = 1
+		store.Update(record.Question, []storage.Record{record})
+	}
+	store.BuildIndex()
+
+	chatbot := &ChatBot{LogicAdapter: logic.NewClosestMatch(store, 1)}
+	if answers := chatbot.Converse("bob", "tomorrow?", "").Answers; len(answers) > 0 && answers[0].Record.State != "" {
+		t.Errorf("expected the follow-up to stay out of the global state, got %v", answers)
+	}
+
+	for _, turn := range [][2]string{
+		{"i want to book a room?", "for which date?"},
+		{"tomorrow?", "smoking or not?"},
+		{"no smoking?", "booked"},
+	} {
+		answers := chatbot.Converse("alice", turn[0], "").Answers
+		if len(answers) == 0 || answers[0].Content != turn[1] {
+			t.Errorf("asked '%s', expected '%s', got %v", turn[0], turn[1], answers)
+		}
+	}
+}
+
+func TestChatBot_ConverseSlots(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.Update("my order did not arrive?", []storage.Record{{
+		Question:   "my order did not arrive?",
+		Answer:     "a ticket was created",
+		CorpusId:   1,
+		Occurrence: 1,
+		Data: map[string]interface{}{
+			"slots": []interface{}{
+				map[string]interface{}{"name": "order", "type": "number", "prompt": "Your order number?", "required": true},
+				map[string]interface{}{"name": "email", "type": "email", "prompt": "Your email?", "required": true},
+			},
+		},
+	}})
+	store.BuildIndex()
+
+	chatbot := &ChatBot{LogicAdapter: logic.NewClosestMatch(store, 1)}
+	if turn := chatbot.Converse("alice", "my order did not arrive?", ""); turn.Prompt != "Your order number?" {
+		t.Fatalf("expected the order number prompt, got %+v", turn)
+	}
+	if turn := chatbot.Converse("alice", "it is 12345", ""); turn.Prompt != "Your email?" || turn.Slots["order"] != "12345" {
+		t.Fatalf("expected the email prompt, got %+v", turn)
+	}
+	if turn := chatbot.Converse("alice", "what?", ""); turn.Prompt != "Your email?" {
+		t.Fatalf("expected the email prompt again, got %+v", turn)
+	}
+
+	turn := chatbot.Converse("alice", "alice@example.com", "")
+	if len(turn.Answers) != 1 || turn.Answers[0].Content != "a ticket was created" ||
+		turn.Slots["order"] != "12345" || turn.Slots["email"] != "alice@example.com" {
+		t.Fatalf("expected the answer with the filled slots, got %+v", turn)
+	}
+	if conversation, _ := chatbot.Sessions.Get("alice"); conversation.Pending != nil {
+		t.Errorf("expected the pending answer to be given, got %+v", conversation)
+	}
+}
+
+func TestChatBot_TrainClassifier(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	chatbot := &ChatBot{StorageAdapter: store, Classifier: logic.NewIntentClassifier()}
+	chatbot.trainClassifier(map[string][]storage.Record{"project": {
+		{Question: "how do i pay the invoice?", Class: "billing"},
+		{Question: "the printer is broken?", Class: "hardware"},
+	}})
+
+	restored := loadIntentClassifier(store)
+	if class, _ := restored.Predict(store.Tokens("invoice")); class != "billing" {
+		t.Errorf("expected the saved classifier to predict billing, got '%s'", class)
+	}
+}
+
+func TestChatBot_Respond(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	for i, record := range []storage.Record{
+		{Question: "opening hours?", Answer: "We are **open** from 9am."},
+		{Question: "where are you?", Answer: " "},
+	} {
+		record.CorpusId = i + 1
+		record.Occurrence = 1
+		store.Update(record.Question, []storage.Record{record})
+	}
+	store.BuildIndex()
+
+	chatbot := &ChatBot{
+		LogicAdapter:   logic.NewClosestMatch(store, 1),
+		OutputAdapters: newOutputAdapters([]string{ADAPTER_MARKDOWN, ADAPTER_TEXT}),
+	}
+
+	statement, turn, err := chatbot.Respond([]byte(`{"text": " opening  hours? ", "user": "alice"}`), "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statement.Session != "alice" || len(turn.Answers) != 1 || turn.Answers[0].Content != "We are open from 9am." {
+		t.Errorf("unexpected reply %+v to %+v", turn, statement)
+	}
+
+	// the empty answer is dropped by the text adapter
+	_, turn, err = chatbot.Respond("where are you?", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(turn.Answers) != 0 || turn.Fallback != chatbot.NoMatchResponse("en") {
+		t.Errorf("expected the no match response, got %+v", turn)
+	}
+
+	if _, _, err := chatbot.Respond(42, "en"); err != input.ErrUnsupported {
+		t.Errorf("expected unsupported request, got %v", err)
+	}
+}
+
+func TestChatBot_SpellCheck(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	question := "how to reset the password?"
+	store.Update(question, []storage.Record{{Question: question, Answer: "use the portal", CorpusId: 1, Occurrence: 1}})
+	store.BuildIndex()
+
+	chatbot := &ChatBot{LogicAdapter: logic.NewClosestMatch(store, 1), StorageAdapter: store}
+	if answers, _ := chatbot.Reply("pasword", ""); len(answers) != 0 {
+		t.Fatalf("expected no answer without spell checking, got %v", answers)
+	}
+
+	chatbot.Config.SpellCheck = true
+	answers, _ := chatbot.Reply("pasword", "")
+	if len(answers) == 0 || answers[0].Content != "use the portal" {
+		t.Fatalf("expected the answer of the corrected question, got %v", answers)
+	}
+	if answers[0].Correction != "password" {
+		t.Errorf("expected the correction to be reported, got '%s'", answers[0].Correction)
+	}
+
+	if answers, _ := chatbot.Reply("password", ""); len(answers) == 0 || answers[0].Correction != "" {
+		t.Errorf("expected no correction for an answered question, got %v", answers)
+	}
+}
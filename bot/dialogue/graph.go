@@ -0,0 +1,135 @@
+package dialogue
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+const (
+	Unreachable = "unreachable"
+	DeadEnd     = "dead_end"
+)
+
+type (
+	// State is a dialogue state of a project. Entries are the global corpora
+	// moving to the state, FollowUps the corpora answering in it and
+	// Transitions the states the follow-ups move to.
+	State struct {
+		Name        string           `json:"name"`
+		Entries     []int            `json:"entries"`
+		FollowUps   []int            `json:"follow_ups"`
+		Transitions map[string][]int `json:"transitions"`
+	}
+
+	// Issue is a problem of a state found by Validate.
+	Issue struct {
+		State   string `json:"state"`
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+	}
+
+	// Graph is the state machine declared by the corpora of a project. A
+	// corpus with a State answers in that state, a contextual corpus moves
+	// the dialogue to its Context.
+	Graph struct {
+		States map[string]*State `json:"states"`
+	}
+)
+
+// NewGraph builds the graph of the records, the records of a corpus with
+// several questions count once.
+func NewGraph(records []storage.Record) *Graph {
+	graph := &Graph{States: make(map[string]*State)}
+	seen := make(map[int]bool)
+	for _, record := range records {
+		if record.CorpusId == 0 || seen[record.CorpusId] {
+			continue
+		}
+		seen[record.CorpusId] = true
+
+		from := record.State
+		if from == "" && !record.Contextual {
+			// answered in its context by storages, like a follow-up
+			from = record.Context
+		}
+		if from != "" {
+			state := graph.state(from)
+			state.FollowUps = append(state.FollowUps, record.CorpusId)
+		}
+
+		if !record.Contextual || record.Context == "" {
+			continue
+		}
+		to := graph.state(record.Context)
+		if from == "" {
+			to.Entries = append(to.Entries, record.CorpusId)
+		} else {
+			state := graph.state(from)
+			state.Transitions[to.Name] = append(state.Transitions[to.Name], record.CorpusId)
+		}
+	}
+	return graph
+}
+
+// Validate reports the states no entry leads to and the states without
+// follow-ups, where only global answers are left.
+func (graph *Graph) Validate() []Issue {
+	reachable := make(map[string]bool)
+	var pending []string
+	for name, state := range graph.States {
+		if len(state.Entries) > 0 {
+			reachable[name] = true
+			pending = append(pending, name)
+		}
+	}
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for to := range graph.States[name].Transitions {
+			if !reachable[to] {
+				reachable[to] = true
+				pending = append(pending, to)
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, name := range graph.names() {
+		state := graph.States[name]
+		if !reachable[name] {
+			issues = append(issues, Issue{
+				State:   name,
+				Kind:    Unreachable,
+				Message: fmt.Sprintf("no entry or transition leads to state '%s'", name),
+			})
+		}
+		if len(state.FollowUps) == 0 {
+			issues = append(issues, Issue{
+				State:   name,
+				Kind:    DeadEnd,
+				Message: fmt.Sprintf("no corpus answers in state '%s'", name),
+			})
+		}
+	}
+	return issues
+}
+
+func (graph *Graph) state(name string) *State {
+	state, ok := graph.States[name]
+	if !ok {
+		state = &State{Name: name, Transitions: make(map[string][]int)}
+		graph.States[name] = state
+	}
+	return state
+}
+
+func (graph *Graph) names() []string {
+	names := make([]string, 0, len(graph.States))
+	for name := range graph.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
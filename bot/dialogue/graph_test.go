@@ -0,0 +1,34 @@
+package dialogue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+func TestGraph_Validate(t *testing.T) {
+	graph := NewGraph([]storage.Record{
+		{CorpusId: 1, Question: "book a room?", Context: "booking", Contextual: true},
+		{CorpusId: 1, Question: "i need a room?", Context: "booking", Contextual: true},
+		{CorpusId: 2, Question: "tomorrow?", State: "booking", Context: "payment", Contextual: true},
+		{CorpusId: 3, Question: "cancel?", State: "booking"},
+		{CorpusId: 4, Question: "refund?", State: "refund"},
+		{CorpusId: 5, Question: "hello?"},
+	})
+
+	booking := graph.States["booking"]
+	if !reflect.DeepEqual(booking.Entries, []int{1}) || !reflect.DeepEqual(booking.FollowUps, []int{2, 3}) ||
+		!reflect.DeepEqual(booking.Transitions, map[string][]int{"payment": {2}}) {
+		t.Errorf("unexpected booking state %+v", booking)
+	}
+
+	var kinds []string
+	for _, issue := range graph.Validate() {
+		kinds = append(kinds, issue.State+" "+issue.Kind)
+	}
+	expected := []string{"payment " + DeadEnd, "refund " + Unreachable}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected %v, got %v", expected, kinds)
+	}
+}
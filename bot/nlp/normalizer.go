@@ -0,0 +1,162 @@
+package nlp
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	NormalizeWidth       = "width"
+	NormalizeNFKC        = "nfkc"
+	NormalizeSimplified  = "simplified"
+	NormalizeLower       = "lower"
+	NormalizePunctuation = "punctuation"
+	NormalizeSpace       = "space"
+	NormalizeStem        = "stem"
+
+	// maxStemPasses bounds the passes stemming a word until it is stable.
+	maxStemPasses = 5
+)
+
+// Normalizer rewrites a text before it is indexed or looked up.
+type Normalizer func(string) string
+
+// normalizeSteps are the steps in the order they always run, whatever the
+// order they are configured in, so that normalizing a normalized text leaves
+// it unchanged.
+var normalizeSteps = []struct {
+	name      string
+	normalize Normalizer
+}{
+	{NormalizeWidth, HalfWidth},
+	{NormalizeNFKC, NFKC},
+	{NormalizeSimplified, Simplified},
+	{NormalizeLower, strings.ToLower},
+	{NormalizePunctuation, StripPunctuation},
+	{NormalizeSpace, CollapseSpace},
+	{NormalizeStem, StemEnglish},
+}
+
+// NewNormalizer chains the named steps, it returns nil when there is none so
+// that texts are kept as they are.
+func NewNormalizer(names []string) (Normalizer, error) {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	var steps []Normalizer
+	for _, step := range normalizeSteps {
+		if selected[step.name] {
+			steps = append(steps, step.normalize)
+			delete(selected, step.name)
+		}
+	}
+	for name := range selected {
+		return nil, fmt.Errorf("unknown normalizer %s", name)
+	}
+
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	return func(text string) string {
+		for _, step := range steps {
+			text = step(text)
+		}
+		return text
+	}, nil
+}
+
+// HalfWidth converts the full-width ASCII characters and the ideographic space
+// to their half-width form.
+func HalfWidth(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xfee0
+		default:
+			return r
+		}
+	}, text)
+}
+
+// NFKC applies the Unicode compatibility composition, like "ﬁ" to "fi".
+func NFKC(text string) string {
+	return norm.NFKC.String(text)
+}
+
+// Simplified converts the Traditional Chinese characters to Simplified ones.
+func Simplified(text string) string {
+	return strings.Map(func(r rune) rune {
+		if simplified, ok := simplifiedTable[r]; ok {
+			return simplified
+		}
+		return r
+	}, text)
+}
+
+// StripPunctuation replaces the punctuation with spaces, apostrophes are
+// dropped so that "what's" becomes "whats".
+func StripPunctuation(text string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '’':
+			return -1
+		case unicode.IsPunct(r):
+			return ' '
+		default:
+			return r
+		}
+	}, text))
+}
+
+// CollapseSpace trims the text and replaces every run of white spaces with
+// one space.
+func CollapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// StemEnglish reduces the English words of the text to their stem, like
+// "running" to "run". Stems are stemmed again until they are stable, so that
+// stemming twice changes nothing.
+func StemEnglish(text string) string {
+	var builder strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			builder.WriteString(stem(text[start:end]))
+			start = -1
+		}
+	}
+
+	for i, r := range text {
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		builder.WriteRune(r)
+	}
+	flush(len(text))
+
+	return builder.String()
+}
+
+func stem(word string) string {
+	for i := 0; i < maxStemPasses; i++ {
+		stemmed := english.Stem(word, false)
+		if stemmed == word {
+			break
+		}
+		word = stemmed
+	}
+	return word
+}
@@ -0,0 +1,37 @@
+package nlp
+
+import "testing"
+
+func TestNewNormalizer(t *testing.T) {
+	normalize, err := NewNormalizer([]string{NormalizeStem, NormalizeSpace, NormalizePunctuation,
+		NormalizeLower, NormalizeSimplified, NormalizeNFKC, NormalizeWidth})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for text, expected := range map[string]string{
+		"Ｈｅｌｌｏ，　Ｗｏｒｌｄ！":              "hello world",
+		"What's   the RUNNING time?": "what the run time",
+		"請問這個價錢是多少？":                 "请问这个价钱是多少",
+		"ﬁle  names":                 "file name",
+		"  ":                         "",
+	} {
+		normalized := normalize(text)
+		if normalized != expected {
+			t.Errorf("expected '%s' to be normalized as '%s', got '%s'", text, expected, normalized)
+		}
+		if again := normalize(normalized); again != normalized {
+			t.Errorf("expected '%s' to be stable, got '%s'", normalized, again)
+		}
+	}
+}
+
+func TestNewNormalizer_Empty(t *testing.T) {
+	if normalize, err := NewNormalizer(nil); normalize != nil || err != nil {
+		t.Errorf("expected no normalizer, got %v", err)
+	}
+
+	if _, err := NewNormalizer([]string{"lower", "unknown"}); err == nil {
+		t.Error("expected an unknown normalizer to fail")
+	}
+}
@@ -0,0 +1,119 @@
+package nlp
+
+// The common Traditional Chinese characters and their Simplified form, at the
+// same position. Characters are converted one by one, which is enough to match
+// questions written in either script.
+const (
+	traditionalCharacters = "" +
+		"萬與專業叢東絲兩嚴喪個豐臨為麗舉麼義烏樂喬習鄉書買亂爭於虧雲亞產畝親億僅從侖倉儀" +
+		"們價眾優夥會傴傘偉傳傷倫偽體餘傭僉俠侶僥偵側僑儈儕儂俁儔儼倆儷儉債傾僂僨償儲兒兌" +
+		"黨蘭關興茲養獸內岡冊寫軍農馮衝決況凍淨涼減湊凜幾鳳憑凱擊鑿芻劃劉則剛創刪別劊劌劑" +
+		"剮劍剝劇勸辦務勱動勵勁勞勢勳勻匭匱區醫華協單賣盧鹵臥衛卻巹廠廳曆歷厲壓厭厙廁廂厴" +
+		"廈廚廄廝縣參雙發變敘疊葉號嘆嘰後嚇呂嗎噸聽啟吳嘸囈嘔嚦唄員咼嗆嗚詠嚨嚀噝響啞噠嘵" +
+		"嗶噦嘩噲嚌噥喲嘜嗊嘮啢嗩喚嘖嗇囀齧囉嘽嘯噴嘍嚳囁噯噓嚶囑嚕團園囪圍圇國圖圓聖壙場" +
+		"壞塊堅壇壢壩塢墳墜壟壚壘墾堊墊埡塏堝塹墮聲壺壼處備復複夠頭誇夾奪奩奐奮獎奬奧妝婦" +
+		"媽嫵嫗媯姍婁婭嬈嬌孌娛媧嫻嫿嬰嬋嬸媼嬡嬪嬙嬤孫學孿寧寶實寵審憲宮寬賓寢對尋導壽將" +
+		"爾塵嘗嚐堯尷屍盡儘層屜屆屬屢屨嶼歲豈嶇崗峴嶴嵐島嶺嶽崬巋嶧峽嶢嶠崢巒嶗崍嶮嶄嶸嶔" +
+		"嶁巔鞏巰幣帥師幃帳簾幟帶幀幫幬幘幗冪莊慶廬廡庫應廟龐廢廩廣開異棄張彌彎彈強歸當噹" +
+		"錄彥徹徑徠憶懺憂愾懷態慫憮慪悵愴憐總懟懌戀懇惡噁慟懨愷惻惱惲悅懸慳憫驚懼慘懲憊愜" +
+		"慚憚慣慍憤憒願懾懣懶戇戔戲戧戰戩戶執擴捫掃揚擾撫拋摶摳掄搶護報擔擬攏揀擁攔擰撥擇" +
+		"掛摯攣撾撻挾撓擋撟掙擠揮撈損撿換搗據擄摑擲撣摻摜攬搵撳攙擱摟攪攜攝攄擺搖擯攤攖撐" +
+		"攆擷擼攛擻攢敵斂數齋斕鬥斬斷無舊時曠暘曇晝顯晉曬曉曄暈暉暫曖術樸機殺雜權條來楊榪" +
+		"傑極構樅樞棗櫪梘棖槍楓梟櫃檸檉梔柵標棧櫛櫳棟櫨櫟欄樹棲樣欒椏橈楨檔榿橋樺檜槳樁夢" +
+		"檢欞槨櫝槧槓樓欖櫬櫚櫸檟檻檳櫧橫檣櫻櫫櫥櫓櫞檁歡歟歐殲歿殤殘殞殮殫殯毆毀轂畢斃氈" +
+		"氌氣氫氬氳匯彙漢湯溝沒灃漚瀝淪滄溈滬濘淚澩瀧瀘濼瀉潑澤涇潔灑窪浹淺漿澆湞濁測澮濟" +
+		"瀏滻渾滸濃潯濤澇淶漣潿渦渙滌潤澗漲澀淵淥漬瀆漸澠漁瀋滲溫灣濕潰濺漵滾滯灩灄滿瀅濾" +
+		"濫灤濱灘澦瀠瀟瀲濰潛瀾灕滅燈靈災燦煬爐燉煒熗點煉鍊熾爍爛烴燭煙煩燒燁燴燙燼熱煥燜" +
+		"燾燄愛爺牘牽犧犢狀獷獁猶狽獮獰獨狹獅獪猙獄猻獫獵獼玀豬貓蝟獻獺獃璣瑪瑋環現瑲璽琺" +
+		"瓏璫琿璉瑣瓊瑤璦瓔瓚甌電畫暢疇癤療瘧癘瘍瘡瘋皰癰痙癢瘂癆瘓癇癡癉瘞瘺癟癱癮癭癩癬" +
+		"癲癒痠皚皺皸盞鹽監蓋盜盤盪瞘眥矚睜睞瞼瞞矯磯礬礦碭碼磚硨硯碸礪礱礫礎碩硤磽確鹼礙" +
+		"磧磣硃禮禕禰禍禎祿禪離祕禿稈種積稱穢穠穩穀稅稟穌窮竊竅窯竄窩窺竇豎競筆筍箋籠箏籌" +
+		"簽籤簡籙籃篩簀籪籟糴類秈糶糲粵糞糧糝餱糰緊縶糾紀紂約紅紆紇紈紉紋納紐紓純紕紗紙級" +
+		"紛紜紡紮細紱紲紳紹紺紼絀終組絆絎結絕絛絞絡絢給絨絰統絹綁綏綆經綃綜綠綢綣綫線練緒" +
+		"續綺綽綾綿緄緇網綱綴綸綵維綹綬緋綻緞締緣編緩緬緯緲緹緻縈縉縊縋縐縑縛縝縞縟縫縭縮" +
+		"縱縲縵縷縹績繃繅繆織繕繚繞繡繩繪繫係繭繯繳繹繼繽纈纏纓纖纘纜緝絃綑缽罈罌罰罵罷羅" +
+		"羆羈羋羥翹耬耮聞聯聰聳職聶聾肅腸膚骯腎腫脹脅膽勝朧腖臚脛膠脈膾髒臟臍腦膿臠腳脫臉" +
+		"臘醃膩騰臏艦艙艫艷豔藝節蘆蘇莧蘋範莖蘢蔦塋煢薦莢蕘蓽蕎薈薺蕩榮葷滎犖熒蕁藎蓀蔭蕒" +
+		"葒葤藥蒞萊蓮蒔萵薟獲蕕瑩鶯蓴蘿螢營蕭薩蔥蕆蕢蔣蔞藍薊蘺驀蘚蘊蕓蘄蘞藺蘗蔔虜慮虛蟲" +
+		"虯蟣雖蝦蠆蝕蟻螞蠶蠔蜆蠱蠣蟶蠻蟄蛺蟯螄蠐蛻蝸蠟蠅蟈蟬蠍螻蠑螿蟎釁銜衆補襯袞襖嫋褘" +
+		"襪襲裝襠褌褳襝褲襉褸襤裡裏製見觀規覓視覘覽覺覬覡覿覦覯覲覷覈觴觸觶計訂訃認譏訐訌" +
+		"討讓訕訖訓議訊記講諱謳詎訝訥許訛論訩訟諷設訪訣證詁訶評詛識詗詐訴診詆謅詞詘詔詖譯" +
+		"詒誆誄試詿詩詰詼誠誅詵話誕詬詮詭詢詣諍該詳詫諢詡譸誡誣語誚誤誥誘誨誑說誦誒請諸諏" +
+		"諾讀諑誹課諉諛誰諗調諂諒諄誶談誼謀諶諜謊諫諧謔謁謂諤諭諼讒諮諳諺諦謎諞謨讜謖謝謠" +
+		"謗諡謙謐謹謾謫譾謬譚譖譙讕譜譎讞譴譫讖註託讚讎貝貞負財貢貧貨販貪貫責貯貰貲貳貴貶" +
+		"貸貺費貼貽貿賀賁賂賃賄賅資賈賊賑賒賧賕賙賚賜賞賠賡賢賤賦質賬賭賴賺賻購賽賾贄贅贈" +
+		"贊贇贍贏贐贓贖贗贛賸趙趕趨趲跡蹟踐踴蹌躂蹺蹕躚躋躍躊蹤躑躡蹣躪躥躦軀車軋軌軒軔軟" +
+		"軫軸軻軼軲軺軹輕載輊輒較輅輇輈輔輛輦輝輩輥輞輪輟輜輯輸輻輳輾輿轀轄轅轆轉轍轎轔轟" +
+		"辭辯邊遼達遷過邁運還這進遠違連遲邇逕適選遜遞邐邏遺遙遊週迴鄧鄺鄔郵鄒鄴鄰鬱郟鄶鄭" +
+		"鄆酈鄖鄲醞醜醬釀釋針釘釗釙釕釤釧釣鈍鈔鈉鈣鈞鈕鈀鈴鉀鉅鉛鉤鉑鉗鉚鉞鉬鈷鉭銀銅銑銓" +
+		"銖銘銬銳銷鋁鋅鋒鋤鋪鋸鋼錐錘錢錦錫錯錳錶鍋鍍鍛鍵鍬鍾鐘鎂鎊鎖鎢鎮鏈鏟鏡鏽鏢鐵鐺鑄" +
+		"鑑鑒鑰鑲鑼鑽長門閂閃閉問闖閏閑閒間閔閘閡閣閤閥閨閩閱閻闊闌闆闈闋闐闔闕闡闢闥隊陽" +
+		"陰陣階際陸隴陳陘陝隉隕險隨隱隸隻雋難雛雞霧霽靂靄靚靜靨韃韁韉韋韌韓韙韜韞韻頁頂頃" +
+		"項順須鬚頊頑顧頓頎頒頌頏預顱領頗頸頡頰頜頻頹頷題額顎顏顓顛顢顫顰顴風颯颱臺檯颳颶" +
+		"飄飆飛飢饑飯飲飩飪飫飭飴飼飽飾餃餅餌餉餓餒餚館餞餡餵饅饋饒饗饜饞餬馬馭馱馳馴駁駐" +
+		"駑駒駕駙駛駝駟駭駱駿騁騎騙騷騾驅驃驕驗驟驢驥驪髏髖髮鬆鬍鬧鬨鬩鬮魘魚魯鮑鮮鯉鯊鯨" +
+		"鰍鱷鱗鳥鳩鳴鴉鴨鴛鴦鴻鵝鵑鵬鶴鷗鷹鸚鸞鹹麥麵麪黃黴齊齒齡齣龍龜乾僕併並倖佈佔嚮塗" +
+		"壎姪孃剋徵恆慾擡捲採摺暱朮殼氾汙洩準滷爲牆瞭箇簑衹妳"
+	simplifiedCharacters = "" +
+		"万与专业丛东丝两严丧个丰临为丽举么义乌乐乔习乡书买乱争于亏云亚产亩亲亿仅从仑仓仪" +
+		"们价众优伙会伛伞伟传伤伦伪体余佣佥侠侣侥侦侧侨侩侪侬俣俦俨俩俪俭债倾偻偾偿储儿兑" +
+		"党兰关兴兹养兽内冈册写军农冯冲决况冻净凉减凑凛几凤凭凯击凿刍划刘则刚创删别刽刿剂" +
+		"剐剑剥剧劝办务劢动励劲劳势勋匀匦匮区医华协单卖卢卤卧卫却卺厂厅历历厉压厌厍厕厢厣" +
+		"厦厨厩厮县参双发变叙叠叶号叹叽后吓吕吗吨听启吴呒呓呕呖呗员呙呛呜咏咙咛咝响哑哒哓" +
+		"哔哕哗哙哜哝哟唛唝唠唡唢唤啧啬啭啮啰啴啸喷喽喾嗫嗳嘘嘤嘱噜团园囱围囵国图圆圣圹场" +
+		"坏块坚坛坜坝坞坟坠垄垆垒垦垩垫垭垲埚堑堕声壶壸处备复复够头夸夹夺奁奂奋奖奖奥妆妇" +
+		"妈妩妪妫姗娄娅娆娇娈娱娲娴婳婴婵婶媪嫒嫔嫱嬷孙学孪宁宝实宠审宪宫宽宾寝对寻导寿将" +
+		"尔尘尝尝尧尴尸尽尽层屉届属屡屦屿岁岂岖岗岘岙岚岛岭岳岽岿峄峡峣峤峥峦崂崃崄崭嵘嵚" +
+		"嵝巅巩巯币帅师帏帐帘帜带帧帮帱帻帼幂庄庆庐庑库应庙庞废廪广开异弃张弥弯弹强归当当" +
+		"录彦彻径徕忆忏忧忾怀态怂怃怄怅怆怜总怼怿恋恳恶恶恸恹恺恻恼恽悦悬悭悯惊惧惨惩惫惬" +
+		"惭惮惯愠愤愦愿慑懑懒戆戋戏戗战戬户执扩扪扫扬扰抚抛抟抠抡抢护报担拟拢拣拥拦拧拨择" +
+		"挂挚挛挝挞挟挠挡挢挣挤挥捞损捡换捣据掳掴掷掸掺掼揽揾揿搀搁搂搅携摄摅摆摇摈摊撄撑" +
+		"撵撷撸撺擞攒敌敛数斋斓斗斩断无旧时旷旸昙昼显晋晒晓晔晕晖暂暧术朴机杀杂权条来杨杩" +
+		"杰极构枞枢枣枥枧枨枪枫枭柜柠柽栀栅标栈栉栊栋栌栎栏树栖样栾桠桡桢档桤桥桦桧桨桩梦" +
+		"检棂椁椟椠杠楼榄榇榈榉槚槛槟槠横樯樱橥橱橹橼檩欢欤欧歼殁殇残殒殓殚殡殴毁毂毕毙毡" +
+		"氇气氢氩氲汇汇汉汤沟没沣沤沥沦沧沩沪泞泪泶泷泸泺泻泼泽泾洁洒洼浃浅浆浇浈浊测浍济" +
+		"浏浐浑浒浓浔涛涝涞涟涠涡涣涤润涧涨涩渊渌渍渎渐渑渔沈渗温湾湿溃溅溆滚滞滟滠满滢滤" +
+		"滥滦滨滩滪潆潇潋潍潜澜漓灭灯灵灾灿炀炉炖炜炝点炼炼炽烁烂烃烛烟烦烧烨烩烫烬热焕焖" +
+		"焘焰爱爷牍牵牺犊状犷犸犹狈狝狞独狭狮狯狰狱狲猃猎猕猡猪猫猬献獭呆玑玛玮环现玱玺珐" +
+		"珑珰珲琏琐琼瑶瑷璎瓒瓯电画畅畴疖疗疟疠疡疮疯疱痈痉痒痖痨痪痫痴瘅瘗瘘瘪瘫瘾瘿癞癣" +
+		"癫愈酸皑皱皲盏盐监盖盗盘荡眍眦瞩睁睐睑瞒矫矶矾矿砀码砖砗砚砜砺砻砾础硕硖硗确硷碍" +
+		"碛碜朱礼祎祢祸祯禄禅离秘秃秆种积称秽秾稳谷税禀稣穷窃窍窑窜窝窥窦竖竞笔笋笺笼筝筹" +
+		"签签简箓篮筛箦簖籁籴类籼粜粝粤粪粮糁糇团紧絷纠纪纣约红纡纥纨纫纹纳纽纾纯纰纱纸级" +
+		"纷纭纺扎细绂绁绅绍绀绋绌终组绊绗结绝绦绞络绚给绒绖统绢绑绥绠经绡综绿绸绻线线练绪" +
+		"续绮绰绫绵绲缁网纲缀纶彩维绺绶绯绽缎缔缘编缓缅纬缈缇致萦缙缢缒绉缣缚缜缟缛缝缡缩" +
+		"纵缧缦缕缥绩绷缫缪织缮缭绕绣绳绘系系茧缳缴绎继缤缬缠缨纤缵缆缉弦捆钵坛罂罚骂罢罗" +
+		"罴羁芈羟翘耧耢闻联聪耸职聂聋肃肠肤肮肾肿胀胁胆胜胧胨胪胫胶脉脍脏脏脐脑脓脔脚脱脸" +
+		"腊腌腻腾膑舰舱舻艳艳艺节芦苏苋苹范茎茏茑茔茕荐荚荛荜荞荟荠荡荣荤荥荦荧荨荩荪荫荬" +
+		"荭荮药莅莱莲莳莴莶获莸莹莺莼萝萤营萧萨葱蒇蒉蒋蒌蓝蓟蓠蓦藓蕴芸蕲蔹蔺蘖卜虏虑虚虫" +
+		"虬虮虽虾虿蚀蚁蚂蚕蚝蚬蛊蛎蛏蛮蛰蛱蛲蛳蛴蜕蜗蜡蝇蝈蝉蝎蝼蝾螀螨衅衔众补衬衮袄袅袆" +
+		"袜袭装裆裈裢裣裤裥褛褴里里制见观规觅视觇览觉觊觋觌觎觏觐觑核觞触觯计订讣认讥讦讧" +
+		"讨让讪讫训议讯记讲讳讴讵讶讷许讹论讻讼讽设访诀证诂诃评诅识诇诈诉诊诋诌词诎诏诐译" +
+		"诒诓诔试诖诗诘诙诚诛诜话诞诟诠诡询诣诤该详诧诨诩诪诫诬语诮误诰诱诲诳说诵诶请诸诹" +
+		"诺读诼诽课诿谀谁谂调谄谅谆谇谈谊谋谌谍谎谏谐谑谒谓谔谕谖谗谘谙谚谛谜谝谟谠谡谢谣" +
+		"谤谥谦谧谨谩谪谫谬谭谮谯谰谱谲谳谴谵谶注托赞仇贝贞负财贡贫货贩贪贯责贮贳赀贰贵贬" +
+		"贷贶费贴贻贸贺贲赂赁贿赅资贾贼赈赊赕赇赒赉赐赏赔赓贤贱赋质账赌赖赚赙购赛赜贽赘赠" +
+		"赞赟赡赢赆赃赎赝赣剩赵赶趋趱迹迹践踊跄跶跷跸跹跻跃踌踪踯蹑蹒躏蹿躜躯车轧轨轩轫软" +
+		"轸轴轲轶轱轺轵轻载轾辄较辂辁辀辅辆辇辉辈辊辋轮辍辎辑输辐辏辗舆辒辖辕辘转辙轿辚轰" +
+		"辞辩边辽达迁过迈运还这进远违连迟迩迳适选逊递逦逻遗遥游周回邓邝邬邮邹邺邻郁郏郐郑" +
+		"郓郦郧郸酝丑酱酿释针钉钊钋钌钐钏钓钝钞钠钙钧钮钯铃钾钜铅钩铂钳铆钺钼钴钽银铜铣铨" +
+		"铢铭铐锐销铝锌锋锄铺锯钢锥锤钱锦锡错锰表锅镀锻键锹钟钟镁镑锁钨镇链铲镜锈镖铁铛铸" +
+		"鉴鉴钥镶锣钻长门闩闪闭问闯闰闲闲间闵闸阂阁合阀闺闽阅阎阔阑板闱阕阗阖阙阐辟闼队阳" +
+		"阴阵阶际陆陇陈陉陕陧陨险随隐隶只隽难雏鸡雾霁雳霭靓静靥鞑缰鞯韦韧韩韪韬韫韵页顶顷" +
+		"项顺须须顼顽顾顿颀颁颂颃预颅领颇颈颉颊颌频颓颔题额颚颜颛颠颟颤颦颧风飒台台台刮飓" +
+		"飘飙飞饥饥饭饮饨饪饫饬饴饲饱饰饺饼饵饷饿馁肴馆饯馅喂馒馈饶飨餍馋糊马驭驮驰驯驳驻" +
+		"驽驹驾驸驶驼驷骇骆骏骋骑骗骚骡驱骠骄验骤驴骥骊髅髋发松胡闹哄阋阄魇鱼鲁鲍鲜鲤鲨鲸" +
+		"鳅鳄鳞鸟鸠鸣鸦鸭鸳鸯鸿鹅鹃鹏鹤鸥鹰鹦鸾咸麦面面黄霉齐齿龄出龙龟干仆并并幸布占向涂" +
+		"埙侄娘克征恒欲抬卷采折昵术壳泛污泄准卤为墙了个蓑只你"
+)
+
+var simplifiedTable = buildSimplifiedTable()
+
+func buildSimplifiedTable() map[rune]rune {
+	traditional := []rune(traditionalCharacters)
+	simplified := []rune(simplifiedCharacters)
+	table := make(map[rune]rune, len(traditional))
+	for i, r := range traditional {
+		table[r] = simplified[i]
+	}
+	return table
+}
@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+const (
+	RESOURCE_SOURCE_DB      = "db"
+	RESOURCE_SOURCE_FILE    = "file"
+	RESOURCE_SOURCE_DEFAULT = "default"
+)
+
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Resource is a dictionary, idf table or stop words resource of a project, see
+// storage.Resources. It replaces the file of the config.
+type Resource struct {
+	Id         int       `json:"id" form:"id" xorm:"int pk autoincr notnull 'id' comment('编号')"`
+	Project    string    `json:"project" form:"project" xorm:"varchar(255) notnull 'project' comment('项目')"`
+	Name       string    `json:"name" form:"name" xorm:"varchar(32) notnull 'name' comment('Resource name')"`
+	Data       []byte    `json:"-" xorm:"longblob 'data' comment('Resource content')"`
+	UpdateTime time.Time `json:"update_time" xorm:"update_time updated" description:"更新时间"`
+}
+
+// ResourceInfo tells where a resource of a project is loaded from.
+type ResourceInfo struct {
+	Name       string     `json:"name"`
+	Source     string     `json:"source"`
+	File       string     `json:"file,omitempty"`
+	Size       int        `json:"size"`
+	UpdateTime *time.Time `json:"update_time,omitempty"`
+}
+
+// resourceFiles returns the files of the resources in the config, in the
+// order of storage.ResourceNames.
+func (conf Config) resourceFiles() []string {
+	return []string{conf.DictFile, conf.IdfFile, conf.StopWordsFile}
+}
+
+// loadResources returns the resources of the project, from the DB first, then
+// from the files of the config. The missing ones are the default ones.
+func loadResources(conf Config) (*storage.Resources, error) {
+	var rows []Resource
+	if engine != nil {
+		if err := engine.Find(&rows, &Resource{Project: conf.Project}); err != nil {
+			return nil, err
+		}
+	}
+
+	files := conf.resourceFiles()
+	for _, row := range rows {
+		for i, name := range storage.ResourceNames {
+			if name == row.Name {
+				files[i] = ""
+			}
+		}
+	}
+	resources, err := storage.ReadResources(files[0], files[1], files[2])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if err := storage.ValidateResource(row.Name, row.Data); err != nil {
+			return nil, fmt.Errorf("invalid resource in the DB: %s", err.Error())
+		}
+		resources.Set(row.Name, row.Data)
+	}
+	if err := resources.RequireDefaults(); err != nil {
+		return nil, fmt.Errorf("%s, set its file in the config of project %s", err.Error(), conf.Project)
+	}
+	return resources, nil
+}
+
+// ReloadResources loads the resources of the project again, with the
+// tokenizer using the dictionary, and builds the index again.
+func (chatbot *ChatBot) ReloadResources() error {
+	tokenizing, ok := chatbot.StorageAdapter.(storage.Tokenizing)
+	if !ok {
+		return nil
+	}
+
+	resources, err := loadResources(chatbot.Config)
+	if err != nil {
+		return err
+	}
+	tokenizer, err := storage.NewTokenizer(chatbot.Config.Tokenizer, chatbot.Config.NgramSize, resources.Dict)
+	if err != nil {
+		return err
+	}
+	if err := tokenizing.SetResources(resources); err != nil {
+		return err
+	}
+	tokenizing.SetTokenizer(tokenizer)
+
+	chatbot.StorageAdapter.BuildIndex()
+	return nil
+}
+
+// IdfReport tells how the terms of the questions are weighted, false when the
+// storage doesn't compute their idf.
+func (chatbot *ChatBot) IdfReport() (storage.IdfReport, bool) {
+	computing, ok := chatbot.StorageAdapter.(storage.IdfComputing)
+	if !ok {
+		return storage.IdfReport{}, false
+	}
+	return computing.IdfReport(), true
+}
+
+// Resource returns the content of the resource of the project, the default
+// one when the project has none.
+func (chatbot *ChatBot) Resource(name string) ([]byte, error) {
+	if err := storage.ValidateResource(name, nil); err != nil {
+		return nil, err
+	}
+
+	resources, err := loadResources(chatbot.Config)
+	if err != nil {
+		return nil, err
+	}
+	if data := resources.Get(name); data != nil {
+		return data, nil
+	}
+	return storage.DefaultResources().Get(name), nil
+}
+
+// SaveResourceToDB replaces a resource of the project with valid data and
+// reloads the resources.
+func (chatbot *ChatBot) SaveResourceToDB(name string, data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%s resource is empty", name)
+	}
+	if err := storage.ValidateResource(name, data); err != nil {
+		return err
+	}
+
+	resource := Resource{Project: chatbot.Config.Project, Name: name}
+	found, err := engine.Get(&resource)
+	if err != nil {
+		return err
+	}
+	resource.Data = data
+	if found {
+		_, err = engine.ID(resource.Id).Cols("data").Update(&resource)
+	} else {
+		_, err = engine.Insert(&resource)
+	}
+	if err != nil {
+		return err
+	}
+	return chatbot.ReloadResources()
+}
+
+// RemoveResourceFromDB deletes a resource of the project, the file of the
+// config or the default resource is used again.
+func (chatbot *ChatBot) RemoveResourceFromDB(name string) error {
+	deleted, err := engine.Delete(&Resource{Project: chatbot.Config.Project, Name: name})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrResourceNotFound
+	}
+	return chatbot.ReloadResources()
+}
+
+// ListResources tells where each resource of the project is loaded from.
+func (chatbot *ChatBot) ListResources() ([]ResourceInfo, error) {
+	var rows []Resource
+	if err := engine.Find(&rows, &Resource{Project: chatbot.Config.Project}); err != nil {
+		return nil, err
+	}
+
+	files := chatbot.Config.resourceFiles()
+	infos := make([]ResourceInfo, len(storage.ResourceNames))
+	for i, name := range storage.ResourceNames {
+		info := ResourceInfo{Name: name, Source: RESOURCE_SOURCE_DEFAULT}
+		if files[i] != "" {
+			info.Source = RESOURCE_SOURCE_FILE
+			info.File = files[i]
+			if stat, err := os.Stat(files[i]); err == nil {
+				info.Size = int(stat.Size())
+			}
+		} else {
+			info.Size = len(storage.DefaultResources().Get(name))
+		}
+		for _, row := range rows {
+			if row.Name == name {
+				updated := row.UpdateTime
+				info = ResourceInfo{Name: name, Source: RESOURCE_SOURCE_DB, Size: len(row.Data), UpdateTime: &updated}
+			}
+		}
+		infos[i] = info
+	}
+	return infos, nil
+}
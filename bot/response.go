@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"strings"
+)
+
+const defaultLanguage = "zh"
+
+var (
+	defaultNoMatchResponses = map[string]string{
+		"en": "Sorry, I could not find an answer, please describe your question in more detail.",
+		"zh": "对不起，没有找答案,请详细描述你的问题（文字不少于15个汉字），\n我们会自动收集你的问题并进行反馈，谢谢！！",
+	}
+	defaultLowConfidenceResponses = map[string]string{
+		"en": "Sorry, I am not sure about the answer, could you rephrase your question?",
+		"zh": "对不起，我不确定答案，请换一种方式描述你的问题。",
+	}
+)
+
+// NoMatchResponse is the response of the project when nothing matches a
+// question, in the first of the languages having one.
+func (chatbot *ChatBot) NoMatchResponse(languages string) string {
+	return chatbot.localize(languages, chatbot.Config.NoMatchResponses, defaultNoMatchResponses)
+}
+
+// LowConfidenceResponse is the response of the project when no answer reaches
+// the minimum confidence, the no match response of a language is used when
+// the language has no low confidence response.
+func (chatbot *ChatBot) LowConfidenceResponse(languages string) string {
+	return chatbot.localize(languages, chatbot.Config.LowConfidenceResponses,
+		chatbot.Config.NoMatchResponses, defaultLowConfidenceResponses)
+}
+
+// localize returns the first response of the languages, then of the language
+// of the project, looking them up in the tables in order.
+func (chatbot *ChatBot) localize(languages string, tables ...map[string]string) string {
+	candidates := parseLanguages(languages)
+	candidates = append(candidates, parseLanguages(chatbot.Config.Language)...)
+	candidates = append(candidates, defaultLanguage)
+
+	for _, language := range candidates {
+		for _, table := range tables {
+			if response, ok := lookupLanguage(table, language); ok {
+				return response
+			}
+		}
+	}
+	return ""
+}
+
+// parseLanguages splits a list like "zh-TW,zh;q=0.9,en;q=0.8", the weights
+// are ignored as browsers send the languages by preference.
+func parseLanguages(languages string) []string {
+	var tags []string
+	for _, each := range strings.Split(languages, ",") {
+		tag := strings.TrimSpace(strings.SplitN(each, ";", 2)[0])
+		if tag != "" && tag != "*" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// lookupLanguage matches the tag case-insensitively, then its base language.
+func lookupLanguage(responses map[string]string, tag string) (string, bool) {
+	base := strings.SplitN(strings.Replace(tag, "_", "-", -1), "-", 2)[0]
+	var fallback string
+	var found bool
+	for language, response := range responses {
+		if strings.EqualFold(language, tag) {
+			return response, true
+		}
+		if !found && strings.EqualFold(language, base) {
+			fallback, found = response, true
+		}
+	}
+	return fallback, found
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+)
+
+type fixedAdapter []logic.Answer
+
+func (fixedAdapter) CanProcess(string) bool { return true }
+
+func (adapter fixedAdapter) Process(string, ...string) []logic.Answer { return adapter }
+
+func (fixedAdapter) SetVerbose() {}
+
+func TestChatBot_Reply(t *testing.T) {
+	chatbot := &ChatBot{
+		LogicAdapter: fixedAdapter{{Content: "a", Confidence: 0.9}, {Content: "b", Confidence: 0.6}, {Content: "c", Confidence: 0.2}},
+		Config: Config{
+			MinConfidence:          0.5,
+			MaxResults:             1,
+			Language:               "en",
+			NoMatchResponses:       map[string]string{"en": "no match", "fr": "aucune réponse"},
+			LowConfidenceResponses: map[string]string{"en": "not sure"},
+		},
+	}
+
+	if answers, fallback := chatbot.Reply("q", ""); len(answers) != 1 || answers[0].Content != "a" || fallback != "" {
+		t.Errorf("expected only the best answer, got %v, '%s'", answers, fallback)
+	}
+
+	chatbot.Config.MinConfidence = 0.95
+	if answers, fallback := chatbot.Reply("q", "fr-CA,fr;q=0.9"); len(answers) != 0 || fallback != "aucune réponse" {
+		t.Errorf("expected the french no match response for low confidences, got %v, '%s'", answers, fallback)
+	}
+	if _, fallback := chatbot.Reply("q", "de"); fallback != "not sure" {
+		t.Errorf("expected the low confidence response of the project language, got '%s'", fallback)
+	}
+
+	chatbot.LogicAdapter = fixedAdapter{}
+	if _, fallback := chatbot.Reply("q", "de"); fallback != "no match" {
+		t.Errorf("expected the no match response, got '%s'", fallback)
+	}
+
+	chatbot.Config = Config{}
+	if _, fallback := chatbot.Reply("q", "en-US"); fallback != defaultNoMatchResponses["en"] {
+		t.Errorf("expected the stock english response, got '%s'", fallback)
+	}
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+)
+
+var ErrRuleNotFound = errors.New("rule not found")
+
+// Rule is a pattern rule of a project, its keywords are comma separated.
+type Rule struct {
+	Id       int    `json:"id" form:"id" xorm:"int pk autoincr notnull 'id' comment('编号')"`
+	Project  string `json:"project" form:"project" xorm:"varchar(255) notnull 'project' comment('项目')"`
+	Name     string `json:"name" form:"name" xorm:"varchar(255) notnull 'name' comment('名称')"`
+	Pattern  string `json:"pattern" form:"pattern" xorm:"varchar(1024) notnull default '' 'pattern' comment('Regular expression')"`
+	Required string `json:"required" form:"required" xorm:"varchar(1024) notnull default '' 'required' comment('Required keywords')"`
+	Excluded string `json:"excluded" form:"excluded" xorm:"varchar(1024) notnull default '' 'excluded' comment('Excluded keywords')"`
+	Answer   string `json:"answer" form:"answer" xorm:"text notnull 'answer' comment('回答')"`
+	Class    string `json:"class" form:"class" xorm:"varchar(255) notnull default '' 'class' comment('分类')"`
+	Priority int    `json:"priority" form:"priority" xorm:"int notnull default 0 'priority' comment('Priority')"`
+}
+
+func (rule *Rule) PatternRule() logic.PatternRule {
+	return logic.PatternRule{
+		Name:     rule.Name,
+		Pattern:  rule.Pattern,
+		Required: splitKeywords(rule.Required),
+		Excluded: splitKeywords(rule.Excluded),
+		Answer:   rule.Answer,
+		Class:    rule.Class,
+		Priority: rule.Priority,
+	}
+}
+
+// LoadRules returns the rules of the project from the DB followed by the ones
+// of Config.RulesFile.
+func (chatbot *ChatBot) LoadRules() ([]logic.PatternRule, error) {
+	var rows []Rule
+	if err := engine.Find(&rows, &Rule{Project: chatbot.Config.Project}); err != nil {
+		return nil, err
+	}
+
+	var rules []logic.PatternRule
+	for _, row := range rows {
+		rules = append(rules, row.PatternRule())
+	}
+
+	if chatbot.Config.RulesFile != "" {
+		fileRules, err := logic.LoadPatternRules(chatbot.Config.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+	return rules, nil
+}
+
+// ReloadRules replaces the rules of the pattern adapter of the project.
+func (chatbot *ChatBot) ReloadRules() error {
+	if chatbot.rules == nil {
+		return nil
+	}
+
+	rules, err := chatbot.LoadRules()
+	if err != nil {
+		return err
+	}
+	return chatbot.rules.SetRules(rules)
+}
+
+// AddRuleToDB saves a valid rule and reloads the rules.
+func (chatbot *ChatBot) AddRuleToDB(rule *Rule) error {
+	rule.Project = chatbot.Config.Project
+	if _, err := logic.NewPatternMatch([]logic.PatternRule{rule.PatternRule()}); err != nil {
+		return err
+	}
+
+	if _, err := engine.Insert(rule); err != nil {
+		return err
+	}
+	return chatbot.ReloadRules()
+}
+
+// RemoveRuleFromDB deletes a rule of the project and reloads the rules.
+func (chatbot *ChatBot) RemoveRuleFromDB(id int) error {
+	deleted, err := engine.Delete(&Rule{Id: id, Project: chatbot.Config.Project})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRuleNotFound
+	}
+	return chatbot.ReloadRules()
+}
+
+// ListRules returns the rules of the project in the DB.
+func (chatbot *ChatBot) ListRules() ([]Rule, error) {
+	var rules []Rule
+	err := engine.Find(&rules, &Rule{Project: chatbot.Config.Project})
+	return rules, err
+}
+
+func splitKeywords(keywords string) []string {
+	var split []string
+	for _, keyword := range strings.Split(keywords, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			split = append(split, keyword)
+		}
+	}
+	return split
+}
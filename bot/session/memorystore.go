@@ -0,0 +1,77 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+const DefaultTTL = 30 * time.Minute
+
+// memoryStore keeps the sessions in memory, the expired ones are dropped when
+// they are read and swept at most once per TTL when sessions are saved.
+type memoryStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	sessions  map[string]*Session
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates a store expiring the sessions that were not saved
+// for ttl, DefaultTTL is used when ttl is not positive.
+func NewMemoryStore(ttl time.Duration) Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &memoryStore{
+		ttl:       ttl,
+		sessions:  make(map[string]*Session),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (store *memoryStore) Get(id string) (*Session, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	session, ok := store.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	if store.expired(session) {
+		delete(store.sessions, id)
+		return nil, false
+	}
+	return session.clone(), true
+}
+
+func (store *memoryStore) Save(session *Session) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.now()
+	saved := session.clone()
+	saved.Updated = now
+	store.sessions[session.Id] = saved
+
+	if now.Sub(store.lastSweep) >= store.ttl {
+		for id, each := range store.sessions {
+			if store.expired(each) {
+				delete(store.sessions, id)
+			}
+		}
+		store.lastSweep = now
+	}
+}
+
+func (store *memoryStore) Delete(id string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.sessions, id)
+}
+
+func (store *memoryStore) expired(session *Session) bool {
+	return store.now().Sub(session.Updated) >= store.ttl
+}
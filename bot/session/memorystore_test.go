@@ -0,0 +1,47 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore(time.Minute).(*memoryStore)
+	store.now = func() time.Time {
+		return now
+	}
+
+	session := New("alice")
+	session.Context = "billing"
+	session.Vars["plan"] = "pro"
+	store.Save(session)
+
+	// the store keeps its own copy
+	session.Vars["plan"] = "free"
+	got, ok := store.Get("alice")
+	if !ok || got.Context != "billing" || got.Vars["plan"] != "pro" {
+		t.Fatalf("expected the saved session, got %v", got)
+	}
+
+	now = now.Add(30 * time.Second)
+	store.Save(New("bob"))
+	now = now.Add(40 * time.Second)
+	if _, ok := store.Get("alice"); ok {
+		t.Error("expected alice to expire")
+	}
+	if _, ok := store.Get("bob"); !ok {
+		t.Error("expected bob to be kept")
+	}
+
+	now = now.Add(2 * time.Minute)
+	store.Save(New("carol"))
+	if _, ok := store.sessions["bob"]; ok {
+		t.Error("expected bob to be swept")
+	}
+
+	store.Delete("carol")
+	if _, ok := store.Get("carol"); ok {
+		t.Error("expected carol to be deleted")
+	}
+}
@@ -0,0 +1,56 @@
+package session
+
+import (
+	"time"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+// Session is the state of a conversation kept by the server between turns.
+type Session struct {
+	Id string `json:"id"`
+	// Context is the context of the last contextual answer, it applies to
+	// the next questions of the conversation.
+	Context string `json:"context"`
+	// Pending is the answer waiting for its required slots, Prompted the
+	// slot asked last and Slots the values filled so far.
+	Pending  *storage.Record        `json:"pending,omitempty"`
+	Prompted string                 `json:"prompted,omitempty"`
+	Slots    map[string]string      `json:"slots,omitempty"`
+	Vars     map[string]interface{} `json:"vars"`
+	Updated  time.Time              `json:"updated"`
+}
+
+// Store keeps the sessions until they expire, Get returns a copy that is
+// written back with Save.
+type Store interface {
+	Get(id string) (*Session, bool)
+	Save(session *Session)
+	Delete(id string)
+}
+
+func New(id string) *Session {
+	return &Session{
+		Id:   id,
+		Vars: make(map[string]interface{}),
+	}
+}
+
+func (session *Session) clone() *Session {
+	copied := *session
+	copied.Vars = make(map[string]interface{}, len(session.Vars))
+	for key, value := range session.Vars {
+		copied.Vars[key] = value
+	}
+	if session.Pending != nil {
+		pending := *session.Pending
+		copied.Pending = &pending
+	}
+	if session.Slots != nil {
+		copied.Slots = make(map[string]string, len(session.Slots))
+		for name, value := range session.Slots {
+			copied.Slots[name] = value
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,147 @@
+package slot
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DataKey is the key of the slots in the data of a corpus.
+const DataKey = "slots"
+
+const (
+	TypeText   = "text"
+	TypeNumber = "number"
+	TypeDate   = "date"
+	TypeEmail  = "email"
+	TypePhone  = "phone"
+)
+
+var typePatterns = map[string]string{
+	TypeNumber: `\d+(?:\.\d+)?`,
+	TypeDate:   `\d{4}[-/.]\d{1,2}[-/.]\d{1,2}|\d{4}年\d{1,2}月\d{1,2}[日号]`,
+	TypeEmail:  `[\w.+-]+@[\w-]+(?:\.[\w-]+)+`,
+	TypePhone:  `\+?\d[\d -]{6,}\d`,
+}
+
+type (
+	// Slot is a value an answer needs before it is given. The value is
+	// the first match of Pattern, or of the pattern of Type, or of its first
+	// group when it has one. Text slots without a pattern take the whole
+	// reply to their prompt.
+	Slot struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Pattern  string `json:"pattern"`
+		Prompt   string `json:"prompt"`
+		Required bool   `json:"required"`
+
+		pattern *regexp.Regexp
+	}
+
+	// Slots are the slots declared by a corpus.
+	Slots []*Slot
+)
+
+// Parse reads the slots in the data of a corpus, a corpus without slots has
+// none and no error.
+func Parse(data map[string]interface{}) (Slots, error) {
+	value, ok := data[DataKey]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var slots Slots
+	if err := json.Unmarshal(encoded, &slots); err != nil {
+		return nil, fmt.Errorf("invalid slots: %s", err.Error())
+	}
+
+	names := make(map[string]bool, len(slots))
+	for _, slot := range slots {
+		if err := slot.compile(); err != nil {
+			return nil, err
+		}
+		if names[slot.Name] {
+			return nil, fmt.Errorf("slot '%s' is declared twice", slot.Name)
+		}
+		names[slot.Name] = true
+	}
+	return slots, nil
+}
+
+func (slot *Slot) compile() error {
+	if slot.Name == "" {
+		return fmt.Errorf("slot without a name")
+	}
+	if slot.Type == "" {
+		slot.Type = TypeText
+	}
+
+	pattern := slot.Pattern
+	if pattern == "" {
+		var ok bool
+		if pattern, ok = typePatterns[slot.Type]; !ok && slot.Type != TypeText {
+			return fmt.Errorf("slot '%s' has an unknown type '%s'", slot.Name, slot.Type)
+		}
+	}
+	if pattern == "" {
+		return nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("slot '%s' has an invalid pattern: %s", slot.Name, err.Error())
+	}
+	slot.pattern = compiled
+	return nil
+}
+
+// Extract finds the value of the slot in the text, prompted tells if the text
+// replies to the prompt of the slot.
+func (slot *Slot) Extract(text string, prompted bool) (string, bool) {
+	if slot.pattern == nil {
+		text = strings.TrimSpace(text)
+		return text, prompted && text != ""
+	}
+
+	match := slot.pattern.FindStringSubmatch(text)
+	if match == nil {
+		return "", false
+	}
+	if len(match) > 1 {
+		return match[1], true
+	}
+	return match[0], true
+}
+
+// Fill extracts the values of the slots missing in filled from the text, and
+// returns how many were found. prompted is the name of the slot whose prompt
+// the text replies to.
+func (slots Slots) Fill(text, prompted string, filled map[string]string) int {
+	var found int
+	for _, slot := range slots {
+		if _, ok := filled[slot.Name]; ok {
+			continue
+		}
+		if value, ok := slot.Extract(text, slot.Name == prompted); ok {
+			filled[slot.Name] = value
+			found++
+		}
+	}
+	return found
+}
+
+// Missing returns the first required slot without a value.
+func (slots Slots) Missing(filled map[string]string) (*Slot, bool) {
+	for _, slot := range slots {
+		if _, ok := filled[slot.Name]; slot.Required && !ok {
+			return slot, true
+		}
+	}
+	return nil, false
+}
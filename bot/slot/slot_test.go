@@ -0,0 +1,64 @@
+package slot
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func parseJson(t *testing.T, data string) (Slots, error) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return Parse(decoded)
+}
+
+func TestSlots_Fill(t *testing.T) {
+	slots, err := parseJson(t, `{"slots": [
+		{"name": "order", "pattern": "#(\\d{6})", "prompt": "Your order number?", "required": true},
+		{"name": "email", "type": "email", "prompt": "Your email?", "required": true},
+		{"name": "comment", "prompt": "Anything else?"}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filled := make(map[string]string)
+	if found := slots.Fill("my order #123456 did not arrive", "", filled); found != 1 || filled["order"] != "123456" {
+		t.Errorf("expected the order number, got %v", filled)
+	}
+
+	missing, ok := slots.Missing(filled)
+	if !ok || missing.Name != "email" || missing.Prompt != "Your email?" {
+		t.Fatalf("expected the email to be missing, got %v", missing)
+	}
+
+	if found := slots.Fill("it is bob@example.com", "email", filled); found != 1 || filled["email"] != "bob@example.com" {
+		t.Errorf("expected the email, got %v", filled)
+	}
+	if _, ok := slots.Missing(filled); ok {
+		t.Errorf("expected every required slot to be filled, got %v", filled)
+	}
+
+	if found := slots.Fill("  left at the door ", "comment", filled); found != 1 || filled["comment"] != "left at the door" {
+		t.Errorf("expected the prompted text slot to take the reply, got %v", filled)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range []string{
+		`{"slots": [{"name": "order", "pattern": "("}]}`,
+		`{"slots": [{"name": "order", "type": "colour"}]}`,
+		`{"slots": [{"pattern": "\\d+"}]}`,
+		`{"slots": [{"name": "a"}, {"name": "a"}]}`,
+		`{"slots": "order"}`,
+	} {
+		if _, err := parseJson(t, data); err == nil {
+			t.Errorf("expected %s to be invalid", data)
+		}
+	}
+
+	if slots, err := parseJson(t, `{"tags": ["a"]}`); err != nil || slots != nil {
+		t.Errorf("expected no slots, got %v, %v", slots, err)
+	}
+}
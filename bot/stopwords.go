@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+// StopWord overrides the detected stop words of a project, an excluded term is
+// never a stop word and the others always are.
+type StopWord struct {
+	Id       int    `json:"id" form:"id" xorm:"int pk autoincr notnull 'id' comment('编号')"`
+	Project  string `json:"project" form:"project" xorm:"varchar(255) notnull 'project' comment('项目')"`
+	Term     string `json:"term" form:"term" xorm:"varchar(255) notnull 'term' comment('Stop word')"`
+	Excluded bool   `json:"excluded" form:"excluded" xorm:"int(1) not null default 0 'excluded' comment('Is the term never a stop word')"`
+}
+
+// StopWordOverrides are the terms always or never stop words in a project.
+type StopWordOverrides struct {
+	Included []string `json:"included"`
+	Excluded []string `json:"excluded"`
+}
+
+// Validate checks that no term is both included and excluded.
+func (overrides *StopWordOverrides) Validate() error {
+	included := make(map[string]bool)
+	for _, term := range overrides.Included {
+		included[strings.ToLower(strings.TrimSpace(term))] = true
+	}
+	for _, term := range overrides.Excluded {
+		if included[strings.ToLower(strings.TrimSpace(term))] {
+			return fmt.Errorf("stop word '%s' is both included and excluded", term)
+		}
+	}
+	return nil
+}
+
+// StopWords returns the stop words of the storage, false when it doesn't
+// detect them.
+func (chatbot *ChatBot) StopWords() (storage.StopWords, bool) {
+	detecting, ok := chatbot.StorageAdapter.(storage.StopWordsDetecting)
+	if !ok {
+		return storage.StopWords{}, false
+	}
+	return detecting.StopWords(), true
+}
+
+// LoadStopWordOverrides returns the stop word overrides of the project in the
+// DB.
+func (chatbot *ChatBot) LoadStopWordOverrides() (StopWordOverrides, error) {
+	var rows []StopWord
+	overrides := StopWordOverrides{Included: []string{}, Excluded: []string{}}
+	if err := engine.Find(&rows, &StopWord{Project: chatbot.Config.Project}); err != nil {
+		return overrides, err
+	}
+
+	for _, row := range rows {
+		if row.Excluded {
+			overrides.Excluded = append(overrides.Excluded, row.Term)
+		} else {
+			overrides.Included = append(overrides.Included, row.Term)
+		}
+	}
+	return overrides, nil
+}
+
+// SaveStopWordOverrides replaces the stop word overrides of the project and
+// sets them on the storage.
+func (chatbot *ChatBot) SaveStopWordOverrides(overrides StopWordOverrides) error {
+	if err := overrides.Validate(); err != nil {
+		return err
+	}
+
+	session := engine.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if _, err := session.Delete(&StopWord{Project: chatbot.Config.Project}); err != nil {
+		session.Rollback()
+		return err
+	}
+	for i, terms := range [][]string{overrides.Included, overrides.Excluded} {
+		for _, term := range terms {
+			if term = strings.TrimSpace(term); term == "" {
+				continue
+			}
+			row := &StopWord{Project: chatbot.Config.Project, Term: term, Excluded: i == 1}
+			if _, err := session.Insert(row); err != nil {
+				session.Rollback()
+				return err
+			}
+		}
+	}
+	if err := session.Commit(); err != nil {
+		return err
+	}
+	return chatbot.loadStopWordOverrides()
+}
+
+// loadStopWordOverrides sets the stop word overrides of the DB on the storage,
+// when it detects stop words.
+func (chatbot *ChatBot) loadStopWordOverrides() error {
+	detecting, ok := chatbot.StorageAdapter.(storage.StopWordsDetecting)
+	if !ok {
+		return nil
+	}
+
+	overrides, err := chatbot.LoadStopWordOverrides()
+	if err != nil {
+		return err
+	}
+	detecting.SetStopWordOverrides(overrides.Included, overrides.Excluded)
+	return nil
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+var ErrSynonymNotFound = errors.New("synonym set not found")
+
+// Synonym is a synonym set of a project, its terms are comma separated.
+type Synonym struct {
+	Id      int    `json:"id" form:"id" xorm:"int pk autoincr notnull 'id' comment('编号')"`
+	Project string `json:"project" form:"project" xorm:"varchar(255) notnull 'project' comment('项目')"`
+	Terms   string `json:"terms" form:"terms" xorm:"varchar(2048) notnull default '' 'terms' comment('Synonyms')"`
+}
+
+// Validate checks that the set has at least two different terms.
+func (synonym *Synonym) Validate() error {
+	if storage.NewSynonyms([][]string{splitKeywords(synonym.Terms)}).Len() == 0 {
+		return fmt.Errorf("synonym set '%s' needs at least two different terms", synonym.Terms)
+	}
+	return nil
+}
+
+// LoadSynonyms returns the synonym sets of the project from the DB.
+func (chatbot *ChatBot) LoadSynonyms() (*storage.Synonyms, error) {
+	rows, err := chatbot.ListSynonyms()
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([][]string, len(rows))
+	for i, row := range rows {
+		sets[i] = splitKeywords(row.Terms)
+	}
+	return storage.NewSynonyms(sets), nil
+}
+
+// ReloadSynonyms replaces the synonyms of the storage, the index is built
+// again when Config.IndexSynonyms is set.
+func (chatbot *ChatBot) ReloadSynonyms() error {
+	if err := chatbot.loadSynonyms(); err != nil {
+		return err
+	}
+
+	if chatbot.Config.IndexSynonyms {
+		chatbot.StorageAdapter.BuildIndex()
+	}
+	return nil
+}
+
+// loadSynonyms sets the synonyms of the storage, when it expands queries.
+func (chatbot *ChatBot) loadSynonyms() error {
+	expanding, ok := chatbot.StorageAdapter.(storage.Expanding)
+	if !ok {
+		return nil
+	}
+
+	synonyms, err := chatbot.LoadSynonyms()
+	if err != nil {
+		return err
+	}
+	expanding.SetSynonyms(synonyms, chatbot.Config.IndexSynonyms)
+	return nil
+}
+
+// AddSynonymToDB saves a valid synonym set and reloads the synonyms.
+func (chatbot *ChatBot) AddSynonymToDB(synonym *Synonym) error {
+	synonym.Project = chatbot.Config.Project
+	if err := synonym.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := engine.Insert(synonym); err != nil {
+		return err
+	}
+	return chatbot.ReloadSynonyms()
+}
+
+// UpdateSynonymInDB replaces the terms of a synonym set of the project and
+// reloads the synonyms.
+func (chatbot *ChatBot) UpdateSynonymInDB(synonym *Synonym) error {
+	synonym.Project = chatbot.Config.Project
+	if err := synonym.Validate(); err != nil {
+		return err
+	}
+
+	updated, err := engine.Where("id = ? AND project = ?", synonym.Id, synonym.Project).
+		Cols("terms").Update(synonym)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrSynonymNotFound
+	}
+	return chatbot.ReloadSynonyms()
+}
+
+// RemoveSynonymFromDB deletes a synonym set of the project and reloads the
+// synonyms.
+func (chatbot *ChatBot) RemoveSynonymFromDB(id int) error {
+	deleted, err := engine.Delete(&Synonym{Id: id, Project: chatbot.Config.Project})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrSynonymNotFound
+	}
+	return chatbot.ReloadSynonyms()
+}
+
+// ListSynonyms returns the synonym sets of the project in the DB.
+func (chatbot *ChatBot) ListSynonyms() ([]Synonym, error) {
+	var synonyms []Synonym
+	err := engine.Find(&synonyms, &Synonym{Project: chatbot.Config.Project})
+	return synonyms, err
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+)
+
+const templateDelimiter = "{{"
+
+// answerValues are the values answer templates refer to, like
+// {{.Slots.order}}, {{.Data.url}}, {{.Vars.name}} or {{.Question}}.
+type answerValues struct {
+	// Question is the question matched by the answer, Input what was asked.
+	Question string
+	Input    string
+	Data     map[string]interface{}
+	Vars     map[string]interface{}
+	Slots    map[string]string
+}
+
+var (
+	answerFuncs = template.FuncMap{
+		"default": func(fallback, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return fallback
+			}
+			return value
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+
+	// answerTemplates caches the parsed templates by answer
+	answerTemplates sync.Map
+)
+
+// parseAnswer parses the answer as a template, answers without actions are
+// static and have no template.
+func parseAnswer(answer string) (*template.Template, error) {
+	if !strings.Contains(answer, templateDelimiter) {
+		return nil, nil
+	}
+	if cached, ok := answerTemplates.Load(answer); ok {
+		return cached.(*template.Template), nil
+	}
+
+	parsed, err := template.New("answer").Funcs(answerFuncs).Option("missingkey=zero").Parse(answer)
+	if err != nil {
+		return nil, err
+	}
+	answerTemplates.Store(answer, parsed)
+	return parsed, nil
+}
+
+// renderAnswer renders the template of the answer, the answer is kept as is
+// when it can't be rendered.
+func renderAnswer(answer string, values answerValues) string {
+	parsed, err := parseAnswer(answer)
+	if err != nil {
+		fmt.Printf("Could not parse answer template '%s': %s\n", answer, err.Error())
+		return answer
+	}
+	if parsed == nil {
+		return answer
+	}
+
+	var rendered strings.Builder
+	if err := parsed.Execute(&rendered, values); err != nil {
+		fmt.Printf("Could not render answer template '%s': %s\n", answer, err.Error())
+		return answer
+	}
+	return rendered.String()
+}
+
+// renderAnswers renders the content of the answers with the data and the
+// question of their record.
+func renderAnswers(answers []logic.Answer, values answerValues) []logic.Answer {
+	for i := range answers {
+		values.Question = answers[i].Record.Question
+		values.Data = answers[i].Record.Data
+		answers[i].Content = renderAnswer(answers[i].Content, values)
+	}
+	return answers
+}
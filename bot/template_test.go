@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+)
+
+func TestRenderAnswers(t *testing.T) {
+	answers := renderAnswers([]logic.Answer{
+		{
+			Content: `{{.Vars.name}}, order {{.Slots.order}} ships from {{.Data.city}} ({{.Question}}) {{default "-" .Slots.missing}}`,
+			Record: storage.Record{
+				Question: "where is my order?",
+				Data:     map[string]interface{}{"city": "Lyon"},
+			},
+		},
+		{Content: "static {answer}"},
+		{Content: "{{.Broken"},
+	}, answerValues{
+		Input: "where is my order",
+		Vars:  map[string]interface{}{"name": "Alice"},
+		Slots: map[string]string{"order": "42"},
+	})
+
+	expected := []string{
+		"Alice, order 42 ships from Lyon (where is my order?) -",
+		"static {answer}",
+		"{{.Broken",
+	}
+	for i, answer := range answers {
+		if answer.Content != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], answer.Content)
+		}
+	}
+}
+
+func TestCorpus_Validate(t *testing.T) {
+	valid := &Corpus{Question: "q", Answer: "hello {{.Vars.name}}"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected a valid template, got %s", err)
+	}
+
+	broken := &Corpus{Question: "q", Answer: "hello {{.Vars.name"}
+	if err := broken.Validate(); err == nil {
+		t.Error("expected the broken template to be rejected")
+	}
+}
@@ -12,7 +12,7 @@ import (
 type (
 	Trainer interface {
 		Train(interface{}) error
-		TrainWithCorpus(corpuses map[string][][]string) error
+		TrainWithCorpus(corpuses map[string][]storage.Record) error
 	}
 
 	ConversationTrainer struct {
@@ -30,24 +30,36 @@ func NewConversationTrainer(storage storage.StorageAdapter) *ConversationTrainer
 	}
 }
 
-func (trainer *ConversationTrainer) getOrCreate(text string) map[string]int {
+func (trainer *ConversationTrainer) getOrCreate(text string) []storage.Record {
 	if value, ok := trainer.storage.Find(text); ok {
 		return value
 	} else {
-		return make(map[string]int)
+		return nil
 	}
 }
 
+// Learn adds the record as an answer to its question, or counts one more
+// occurrence if the question already has the same answer.
+func (trainer *ConversationTrainer) Learn(record storage.Record) {
+	responses := trainer.getOrCreate(record.Question)
+	for i := range responses {
+		if responses[i].Same(record) {
+			responses[i].Occurrence++
+			trainer.storage.Update(record.Question, responses)
+			return
+		}
+	}
+
+	record.Occurrence = 1
+	trainer.storage.Update(record.Question, append(responses, record))
+}
+
 func (trainer *ConversationTrainer) Train(data interface{}) error {
 	sentences, ok := data.([]string)
 	if !ok {
 		return errors.New("ConversationTrainer.Train needs arguments to be []string")
 	}
 
-	//if len(sentences) == 2 {
-	//	sentences[1] = sentences[1] //fmt.Sprintf("%s$$$$%s", sentences[0], sentences[1])
-	//}
-
 	var history string
 	for _, sentence := range sentences {
 		sentence = strings.TrimSpace(sentence)
@@ -56,9 +68,10 @@ func (trainer *ConversationTrainer) Train(data interface{}) error {
 		}
 
 		if len(history) > 0 {
-			responses := trainer.getOrCreate(history)
-			responses[sentence] += 1
-			trainer.storage.Update(history, responses)
+			trainer.Learn(storage.Record{
+				Question: history,
+				Answer:   sentence,
+			})
 		}
 
 		history = sentence
@@ -73,14 +86,14 @@ func NewCorpusTrainer(storage storage.StorageAdapter) *CorpusTrainer {
 	}
 }
 
-func (trainer *CorpusTrainer) TrainWithCorpus(corpuses map[string][][]string) error {
+func (trainer *CorpusTrainer) TrainWithCorpus(corpuses map[string][]storage.Record) error {
 	fmt.Printf("Training with %d corpuses\n", len(corpuses))
 	convTrainer := NewConversationTrainer(trainer.storage)
 
-	for _, convs := range corpuses {
-		fmt.Printf("Have %d conversations in this corpus\n", len(convs))
-		for _, conv := range convs {
-			convTrainer.Train(conv)
+	for _, records := range corpuses {
+		fmt.Printf("Have %d conversations in this corpus\n", len(records))
+		for _, record := range records {
+			convTrainer.Learn(record)
 		}
 	}
 	trainer.storage.BuildIndex()
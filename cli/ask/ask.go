@@ -4,31 +4,94 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/jeffdoubleyou/chatbot/bot"
 	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
 	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
 )
 
 var (
-	verbose   = flag.Bool("v", false, "verbose mode")
-	storeFile = flag.String("c", "corpus.gob", "the file to store corpora")
-	tops      = flag.Int("t", 5, "the number of answers to return")
+	verbose       = flag.Bool("v", false, "verbose mode")
+	storeFile     = flag.String("c", "corpus.gob", "the file to store corpora, .bolt files are bolt storages")
+	tops          = flag.Int("t", 5, "the number of answers to return")
+	minScore      = flag.Float64("min", 0, "the minimum confidence of an answer")
+	language      = flag.String("l", "", "the language of the default responses, like en or zh")
+	normalizers   = flag.String("normalize", "", "the normalizers the corpora were trained with, comma to separate multiple ones")
+	tokenizerName = flag.String("tokenizer", "", "the tokenizer the corpora were trained with, jieba, word or ngram")
+	dictFile      = flag.String("dict", "", "the jieba dictionary the corpora were trained with, dict.txt by default")
+	idfFile       = flag.String("idf", "", "the idf table the corpora were trained with, idf.txt by default")
+	stopWordsFile = flag.String("stopwords", "", "the stop words the corpora were trained with, stop_words.txt by default")
+	idfMode       = flag.String("idfmode", "", "the idf the corpora were trained with, stock, corpus or blend")
 )
 
 func main() {
 	flag.Parse()
 
-	store, err := storage.NewSeparatedMemoryStorage(*storeFile)
+	store, err := storage.Open(*storeFile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if *normalizers != "" {
+		normalizer, err := nlp.NewNormalizer(strings.Split(*normalizers, ","))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if normalizing, ok := store.(storage.Normalizing); ok {
+			normalizing.SetNormalizer(normalizer)
+		}
+	}
+	resources, err := storage.ReadResources(*dictFile, *idfFile, *stopWordsFile)
+	if err == nil {
+		err = resources.RequireDefaults()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tokenizing, ok := store.(storage.Tokenizing); ok {
+		if err := tokenizing.SetResources(resources); err != nil {
+			log.Fatal(err)
+		}
+		if *tokenizerName != "" || resources.Dict != nil {
+			tokenizer, err := storage.NewTokenizer(*tokenizerName, 0, resources.Dict)
+			if err != nil {
+				log.Fatal(err)
+			}
+			tokenizing.SetTokenizer(tokenizer)
+		}
+	}
+	if computing, ok := store.(storage.IdfComputing); ok {
+		if err := computing.SetIdfOptions(storage.IdfOptions{Mode: *idfMode}); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	adapter := logic.NewClosestMatch(store, *tops)
+	if models, ok := store.(storage.ModelStore); ok {
+		if data, ok, _ := models.LoadModel(logic.IntentModelName); ok {
+			classifier := logic.NewIntentClassifier()
+			if err := classifier.UnmarshalBinary(data); err != nil {
+				log.Fatal(err)
+			}
+			adapter = logic.NewIntentMatch(adapter, store, classifier, false, 0)
+		}
+	}
 
 	chatbot := &bot.ChatBot{
-		LogicAdapter: logic.NewClosestMatch(store, *tops),
+		LogicAdapter: adapter,
+		Config: bot.Config{
+			MinConfidence: float32(*minScore),
+			MaxResults:    *tops,
+			Language:      *language,
+		},
 	}
 	if *verbose {
 		chatbot.LogicAdapter.SetVerbose()
@@ -44,11 +107,22 @@ func main() {
 		}
 
 		startTime := time.Now()
-		answers := chatbot.GetResponse(question)
+		turn := chatbot.Converse("ask", question, *language)
+		if turn.Prompt != "" {
+			fmt.Printf("A: %s\n", turn.Prompt)
+			continue
+		}
+		answers := turn.Answers
 		if len(answers) == 0 {
-			fmt.Println("No answer!")
+			fmt.Printf("A: %s\n", turn.Fallback)
 			continue
 		}
+		if *verbose && answers[0].Intent != "" {
+			fmt.Printf("Intent: %s\tProbability: %.3f\n", answers[0].Intent, answers[0].IntentProbability)
+		}
+		if len(turn.Slots) > 0 {
+			fmt.Printf("Slots: %v\n", turn.Slots)
+		}
 
 		if *tops == 1 {
 			fmt.Printf("A: %s\n", answers[0].Content)
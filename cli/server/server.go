@@ -5,7 +5,6 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +14,7 @@ import (
 	"github.com/gobuffalo/packr"
 	"github.com/jeffdoubleyou/chatbot/bot"
 	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
 )
 
 var factory *bot.ChatBotFactory
@@ -40,10 +40,16 @@ type JsonResult struct {
 }
 
 type QA struct {
-	Question string  `json:"question"`
-	Answer   string  `json:"answer"`
-	Score    float32 `json:"score"`
-	ID       int     `json:"id"`
+	Question string            `json:"question"`
+	Answer   string            `json:"answer"`
+	Score    float32           `json:"score"`
+	ID       int               `json:"id"`
+	Slots    map[string]string `json:"slots,omitempty"`
+	Intent   string            `json:"intent,omitempty"`
+	// Expansions are the synonyms of the question that matched the answer.
+	Expansions []storage.Expansion `json:"expansions,omitempty"`
+	// DidYouMean is the corrected question the answer was found for.
+	DidYouMean string `json:"did_you_mean,omitempty"`
 }
 
 type ResoveReq struct {
@@ -81,15 +87,16 @@ func bindRounter(router *gin.Engine) {
 	buildAnswer := func(answers []logic.Answer) []QA {
 		var qas []QA
 		for _, answer := range answers {
-			contents := strings.Split(answer.Content, "$$$$")
-			if len(contents) > 2 {
-				qa := QA{
-					Question: contents[0],
-					Answer:   contents[1],
-					Score:    answer.Confidence,
-				}
-				qa.ID, _ = strconv.Atoi(contents[2])
-				qas = append(qas, qa)
+			if answer.Record.CorpusId > 0 || answer.Rule != "" || answer.Adapter != "" {
+				qas = append(qas, QA{
+					Question:   answer.Record.Question,
+					Answer:     answer.Content,
+					Score:      answer.Confidence,
+					ID:         answer.Record.CorpusId,
+					Intent:     answer.Intent,
+					Expansions: answer.Expansions,
+					DidYouMean: answer.Correction,
+				})
 			}
 		}
 		return qas
@@ -118,23 +125,10 @@ func bindRounter(router *gin.Engine) {
 		if err != nil {
 			return
 		}
-		answer := make(map[string]int)
-		exp, err := regexp.Compile(`[|｜\r\n]+`)
-		if err != nil {
-			return
+		for _, record := range corpus.Records() {
+			record.Occurrence = 1
+			chatbot.StorageAdapter.Update(record.Question, []storage.Record{record})
 		}
-		questions := exp.Split(corpus.Question, -1)
-		for _, question := range questions {
-			if strings.TrimSpace(question) == "" {
-				continue
-			}
-			if !strings.HasSuffix(question, "?") && !strings.HasSuffix(question, "？") {
-				question = question + "?"
-			}
-			answer[fmt.Sprintf("%s$$$$%s$$$$%v", question, corpus.Answer, corpus.Id)] = 1
-			chatbot.StorageAdapter.Update(question, answer)
-		}
-		chatbot.StorageAdapter.BuildIndex()
 	})
 
 	v1.GET("search", func(context *gin.Context) {
@@ -163,8 +157,27 @@ func bindRounter(router *gin.Engine) {
 		if !strings.HasSuffix(q, "?") && !strings.HasSuffix(q, "？") {
 			q = q + "?"
 		}
-		results := chatbot.GetResponse(q)
+		languages := context.Query("lang")
+		if languages == "" {
+			languages = context.GetHeader("Accept-Language")
+		}
+		var results []logic.Answer
+		var fallback string
+		var slots map[string]string
+		if sessionId := context.Query("session"); sessionId != "" {
+			turn := chatbot.Converse(sessionId, q, languages)
+			results, fallback, slots = turn.Answers, turn.Fallback, turn.Slots
+			if turn.Prompt != "" {
+				data = []QA{{Question: q, Answer: turn.Prompt, Slots: slots}}
+				return
+			}
+		} else {
+			results, fallback = chatbot.Reply(q, languages)
+		}
 		qas := buildAnswer(results)
+		if len(qas) > 0 {
+			qas[0].Slots = slots
+		}
 		fmt.Printf("Q: %s\n", q)
 		fmt.Printf("RESULT: %v\n", qas)
 		if len(qas) > 0 {
@@ -175,9 +188,10 @@ func bindRounter(router *gin.Engine) {
 			}
 			chatbot.AddFeedbackToDB(&feedback)
 		} else {
-			answer := "对不起，没有找答案,请详细描述你的问题（文字不少于15个汉字），\n我们会自动收集你的问题并进行反馈，谢谢！！"
+			if fallback == "" {
+				fallback = chatbot.NoMatchResponse(languages)
+			}
 			if len(q) > 45 {
-				answer = "对不起，没有找答案,你的问题我已经记录并反馈，无需重复提交，谢谢！！！。"
 				feedback := bot.Feedback{
 					Question: q,
 					Answer:   "",
@@ -186,7 +200,7 @@ func bindRounter(router *gin.Engine) {
 				chatbot.AddFeedbackToDB(&feedback)
 			}
 			qa := QA{
-				Answer:   answer,
+				Answer:   fallback,
 				Question: q,
 			}
 			qas = append(qas, qa)
@@ -211,7 +225,6 @@ func bindRounter(router *gin.Engine) {
 		if err != nil {
 			return
 		}
-		chatbot.StorageAdapter.BuildIndex()
 	})
 
 	v1.GET("list/project", func(context *gin.Context) {
@@ -3,12 +3,15 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"path/filepath"
 	"strings"
 
 	"github.com/jeffdoubleyou/chatbot/bot"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
 	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+	"github.com/jeffdoubleyou/chatbot/bot/nlp"
 )
 
 var (
@@ -17,8 +20,15 @@ var (
 	//sqliteDB      = flag.String("sqlite3", "", "the file path of the corpus sqlite3")
 	project       = flag.String("project", "DMS", "the name of the project in sqlite3 db")
 	corpora       = flag.String("i", "", "the corpora files, comma to separate multiple files")
-	storeFile     = flag.String("o", "corpus.gob", "the file to store corpora")
+	storeFile     = flag.String("o", "corpus.gob", "the file to store corpora, .bolt files are bolt storages")
 	printMemStats = flag.Bool("m", false, "enable printing memory stats")
+	intent        = flag.Bool("intent", false, "train the intent classifier with the corpus classes")
+	normalizers   = flag.String("normalize", "", "the normalizers of the questions, comma to separate multiple ones")
+	tokenizerName = flag.String("tokenizer", "", "the tokenizer of the questions, jieba, word or ngram")
+	dictFile      = flag.String("dict", "", "the jieba dictionary of the questions, dict.txt by default")
+	idfFile       = flag.String("idf", "", "the idf table of the questions, idf.txt by default")
+	stopWordsFile = flag.String("stopwords", "", "the stop words of the questions, stop_words.txt by default")
+	idfMode       = flag.String("idfmode", "", "the idf of the questions, stock, corpus or blend")
 )
 
 func main() {
@@ -44,21 +54,62 @@ func main() {
 		return
 	}
 
-	store, err := storage.NewSeparatedMemoryStorage(*storeFile)
+	store, err := storage.Open(*storeFile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if *normalizers != "" {
+		normalizer, err := nlp.NewNormalizer(strings.Split(*normalizers, ","))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if normalizing, ok := store.(storage.Normalizing); ok {
+			normalizing.SetNormalizer(normalizer)
+		}
+	}
+	resources, err := storage.ReadResources(*dictFile, *idfFile, *stopWordsFile)
+	if err == nil {
+		err = resources.RequireDefaults()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tokenizing, ok := store.(storage.Tokenizing); ok {
+		if err := tokenizing.SetResources(resources); err != nil {
+			log.Fatal(err)
+		}
+		if *tokenizerName != "" || resources.Dict != nil {
+			tokenizer, err := storage.NewTokenizer(*tokenizerName, 0, resources.Dict)
+			if err != nil {
+				log.Fatal(err)
+			}
+			tokenizing.SetTokenizer(tokenizer)
+		}
+	}
+	if computing, ok := store.(storage.IdfComputing); ok {
+		if err := computing.SetIdfOptions(storage.IdfOptions{Mode: *idfMode}); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	chatbot := &bot.ChatBot{
 		PrintMemStats:  *printMemStats,
 		Trainer:        bot.NewCorpusTrainer(store),
 		StorageAdapter: store,
 		Config: bot.Config{
+			Intent:     *intent,
 			Project:    *project,
 			Driver:     "sqlite3",
 			DataSource: "chatbot.db",
 		},
 	}
+	if *intent {
+		chatbot.Classifier = logic.NewIntentClassifier()
+	}
+
 	if len(strings.Split(corporaFiles, ",")) > 0 {
 		corpuses, err := chatbot.LoadCorpusFromFiles(strings.Split(corporaFiles, ","))
 		if err == nil {
@@ -69,6 +120,9 @@ func main() {
 		if err := chatbot.TrainWithDB(); err != nil {
 			log.Fatal(err)
 		}
+		if err := store.Sync(); err != nil {
+			log.Fatal(err)
+		}
 	} else {
 		if err := chatbot.Train(strings.Split(corporaFiles, ",")); err != nil {
 			log.Fatal(err)
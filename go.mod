@@ -14,13 +14,16 @@ require (
 	github.com/google/uuid v1.1.2
 	github.com/gorilla/mux v1.6.2
 	github.com/joho/godotenv v1.4.0 // indirect
+	github.com/kljensen/snowball v0.6.0
 	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/prometheus/common v0.26.0
 	github.com/rogpeppe/go-internal v1.8.0 // indirect
 	github.com/sirupsen/logrus v1.8.1 // indirect
 	github.com/tal-tech/go-zero v1.2.1
 	github.com/wangbin/jiebago v0.3.2
+	go.etcd.io/bbolt v1.3.6
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
 	golang.org/x/sys v0.0.0-20211019181941-9d821ace8654 // indirect
+	golang.org/x/text v0.3.6
 	gopkg.in/yaml.v2 v2.4.0
 )
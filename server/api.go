@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/jeffdoubleyou/chatbot/bot"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/storage"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
@@ -36,15 +39,29 @@ type QA struct {
 	Score      float32                `json:"score"`
 	Context    string                 `json:"context"`
 	Contextual bool                   `json:"contextual"`
-	Class      string                 `json:"class"'`
-	Data       map[string]interface{} `json:"data"'`
+	State      string                 `json:"state"`
+	Class      string                 `json:"class"`
+	Data       map[string]interface{} `json:"data"`
 	ID         int                    `json:"id"`
+	Rule       string                 `json:"rule,omitempty"`
+	Adapter    string                 `json:"adapter,omitempty"`
+	// Expansions are the synonyms of the question that matched the answer.
+	Expansions []storage.Expansion `json:"expansions,omitempty"`
 }
 
 type Response struct {
 	Question string `json:"question"`
+	Session  string `json:"session,omitempty"`
 	Results  []*QA  `json:"results"`
 	Message  string `json:"message"`
+	// Prompt asks for a missing slot of the answer, Slots are the filled ones.
+	Prompt string            `json:"prompt,omitempty"`
+	Slots  map[string]string `json:"slots,omitempty"`
+	// Intent is the class predicted for the question by the project.
+	Intent            string  `json:"intent,omitempty"`
+	IntentProbability float64 `json:"intent_probability,omitempty"`
+	// DidYouMean is the corrected question the results were found for.
+	DidYouMean string `json:"did_you_mean,omitempty"`
 }
 
 type ResoveReq struct {
@@ -52,7 +69,12 @@ type ResoveReq struct {
 	Id   int  `json:"id"`
 }
 
-func init() {
+type FeedbackReq struct {
+	bot.Feedback
+	IsOk *bool `json:"is_ok"`
+}
+
+func main() {
 	flag.Parse()
 	config := bot.Config{
 		Driver:        *driver,
@@ -64,9 +86,22 @@ func init() {
 	}
 	factory = bot.NewChatBotFactory(config)
 	factory.Init()
+
+	serverAddress := fmt.Sprintf("%s:%d", *listenAddr, *listenPort)
+
+	srv := &http.Server{
+		Handler:      newRouter(),
+		Addr:         serverAddress,
+		WriteTimeout: 15 * time.Second,
+		ReadTimeout:  15 * time.Second,
+	}
+
+	fmt.Printf("Starting server on %s\n", serverAddress)
+	srv.ListenAndServe()
 }
 
-func main() {
+// newRouter routes the API and the docs to the handlers.
+func newRouter() *mux.Router {
 	router := mux.NewRouter()
 	fs := http.FileServer(http.Dir("./docs/"))
 	router.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", fs))
@@ -78,6 +113,9 @@ func main() {
 	project.Path("/").Methods("POST").HandlerFunc(addProject)
 	project.Path("/{project}").Methods("DELETE").HandlerFunc(deleteProject)
 	project.Path("/{project}/train").Methods("GET").HandlerFunc(trainProject)
+	project.Path("/{project}/dialogue").Methods("GET").HandlerFunc(getProjectDialogue)
+	project.Path("/{project}/idf").Methods("GET").HandlerFunc(getProjectIdf)
+	project.Path("/{project}/explain").Methods("GET").HandlerFunc(explainProjectAnswers)
 
 	// Corpus
 	corpus := router.PathPrefix("/corpus/").Subrouter()
@@ -87,21 +125,38 @@ func main() {
 	corpus.Path("/{project}/{id}").Methods("DELETE").HandlerFunc(deleteProjectCorpus)
 	corpus.Path("/{project}/{id}").Methods("PUT").HandlerFunc(updateProjectCorpus)
 
+	// Rules
+	rules := router.PathPrefix("/rules/").Subrouter()
+	rules.Path("/{project}").Methods("GET").HandlerFunc(listProjectRules)
+	rules.Path("/{project}").Methods("POST").HandlerFunc(addProjectRule)
+	rules.Path("/{project}/{id}").Methods("DELETE").HandlerFunc(deleteProjectRule)
+
+	// Synonyms
+	synonyms := router.PathPrefix("/synonyms/").Subrouter()
+	synonyms.Path("/{project}").Methods("GET").HandlerFunc(listProjectSynonyms)
+	synonyms.Path("/{project}").Methods("POST").HandlerFunc(addProjectSynonym)
+	synonyms.Path("/{project}/{id}").Methods("PUT").HandlerFunc(updateProjectSynonym)
+	synonyms.Path("/{project}/{id}").Methods("DELETE").HandlerFunc(deleteProjectSynonym)
+
+	// Stop words detected in the questions, with their overrides
+	stopWords := router.PathPrefix("/stopwords/").Subrouter()
+	stopWords.Path("/{project}").Methods("GET").HandlerFunc(getProjectStopWords)
+	stopWords.Path("/{project}").Methods("PUT").HandlerFunc(updateProjectStopWords)
+
+	// Dictionary, idf and stop words resources
+	resources := router.PathPrefix("/resources/").Subrouter()
+	resources.Path("/{project}").Methods("GET").HandlerFunc(listProjectResources)
+	resources.Path("/{project}/reload").Methods("POST").HandlerFunc(reloadProjectResources)
+	resources.Path("/{project}/{name}").Methods("GET").HandlerFunc(getProjectResource)
+	resources.Path("/{project}/{name}").Methods("PUT").HandlerFunc(updateProjectResource)
+	resources.Path("/{project}/{name}").Methods("DELETE").HandlerFunc(deleteProjectResource)
+
 	respond := router.PathPrefix("/respond/").Subrouter()
 	respond.Path("/{project}").Methods("GET").HandlerFunc(getResponse)
+	respond.Path("/{project}").Methods("POST").HandlerFunc(postResponse)
 	respond.Path("/feedback/{project}").Methods("POST").HandlerFunc(addFeedback)
 
-	serverAddress := fmt.Sprintf("%s:%d", *listenAddr, *listenPort)
-
-	srv := &http.Server{
-		Handler:      router,
-		Addr:         serverAddress,
-		WriteTimeout: 15 * time.Second,
-		ReadTimeout:  15 * time.Second,
-	}
-
-	fmt.Printf("Starting server on %s\n", serverAddress)
-	srv.ListenAndServe()
+	return router
 }
 
 func trainProject(writer http.ResponseWriter, request *http.Request) {
@@ -112,11 +167,15 @@ func trainProject(writer http.ResponseWriter, request *http.Request) {
 		return
 	} else {
 		fmt.Printf("Loaded chat bot for project %s\n", project)
+		if err := bot.ReloadRules(); err != nil {
+			SendError(writer, err.Error(), 500)
+			return
+		}
 		if err := bot.TrainWithDB(); err != nil {
 			SendError(writer, err.Error(), 500)
 			return
 		} else {
-			SendJson(writer, map[string]interface{}{"result":"ok"})
+			SendJson(writer, map[string]interface{}{"result": "ok"})
 		}
 	}
 }
@@ -125,7 +184,7 @@ func getProjectCorpusById(writer http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	id, _ := strconv.Atoi(vars["id"])
 	project := vars["project"]
-	fmt.Printf("Get corpus ID %s from project %s\n", id, project)
+	fmt.Printf("Get corpus ID %d from project %s\n", id, project)
 	corp := factory.GetCorpusById(id)
 	if corp != nil && corp.Project == project {
 		SendJson(writer, corp)
@@ -135,7 +194,44 @@ func getProjectCorpusById(writer http.ResponseWriter, request *http.Request) {
 }
 
 func addFeedback(writer http.ResponseWriter, request *http.Request) {
+	var feedback FeedbackReq
+	if err := ParseJsonBody(request, &feedback); err != nil {
+		SendError(writer, fmt.Sprintf("Unable to parse request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(request)
+	project := vars["project"]
+
+	if feedback.Cid <= 0 && feedback.Question == "" {
+		SendError(writer, "Either cid or question must be provided", http.StatusBadRequest)
+		return
+	}
+
+	chatbot, ok := getProjectChatBot(writer, project)
+	if !ok {
+		return
+	}
+
+	if feedback.Cid > 0 {
+		corp := factory.GetCorpusById(feedback.Cid)
+		if corp == nil || corp.Project != project {
+			SendError(writer, "Corpus not found", http.StatusNotFound)
+			return
+		}
+		if feedback.IsOk != nil {
+			if err := factory.UpdateCorpusCounter(feedback.Cid, *feedback.IsOk); err != nil {
+				SendError(writer, fmt.Sprintf("Could not update corpus counter: %s", err.Error()), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
 
+	feedback.Project = project
+	if err := chatbot.AddFeedbackToDB(&feedback.Feedback); err != nil {
+		SendError(writer, fmt.Sprintf("Could not add feedback to database: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, feedback.Feedback)
 }
 
 func getResponse(writer http.ResponseWriter, request *http.Request) {
@@ -145,9 +241,11 @@ func getResponse(writer http.ResponseWriter, request *http.Request) {
 
 	query := request.Form.Get("q")
 	context := request.Form.Get("context")
+	sessionId := request.Form.Get("session")
 
 	response := &Response{
 		Question: query,
+		Session:  sessionId,
 		Results:  nil,
 		Message:  "",
 	}
@@ -168,38 +266,444 @@ func getResponse(writer http.ResponseWriter, request *http.Request) {
 			c = []string{context}
 		}
 
-		answers := bot.GetResponse(query, c...)
-		j, _ := json.MarshalIndent(answers, "", "\t")
-		fmt.Printf("RES: %s\n", j)
-		for _, answer := range answers {
-			contents := strings.Split(answer.Content, "$$$$")
-			if len(contents) > 2 {
-				id, _ := strconv.Atoi(contents[2])
-				corpus := factory.GetCorpusById(id)
-				qa := &QA{
-					Question:   contents[0],
-					Answer:     contents[1],
-					Score:      answer.Confidence,
-					Context:    corpus.Context,
-					Contextual: corpus.Contextual,
-					Data:       corpus.Data.Data,
-					Class:      corpus.Class,
-					ID:         id,
-				}
-				response.Results = append(response.Results, qa)
-			}
+		languages := request.Form.Get("lang")
+		if languages == "" {
+			languages = request.Header.Get("Accept-Language")
+		}
+
+		var answers []logic.Answer
+		var fallback string
+		if sessionId != "" {
+			turn := bot.Converse(sessionId, query, languages, c...)
+			answers, fallback = turn.Answers, turn.Fallback
+			response.Prompt = turn.Prompt
+			response.Slots = turn.Slots
+		} else {
+			answers, fallback = bot.Reply(query, languages, c...)
+		}
+		response.Message = fallback
+		response.Intent, response.IntentProbability = bot.Classify(query)
+		response.Results = answerResults(answers)
+		response.DidYouMean = didYouMean(answers)
+		if len(response.Results) == 0 && response.Message == "" && response.Prompt == "" {
+			response.Message = bot.NoMatchResponse(languages)
 		}
 		SendJson(writer, response)
 		return
 	}
 }
 
+// postResponse replies to a request read by the input adapters of the
+// project, like a JSON message or the payload of a channel.
+func postResponse(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	project := vars["project"]
+
+	bot, ok := factory.GetChatBot(project)
+	if !ok {
+		SendError(writer, "Could not initialize project", http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		SendError(writer, "Could not read request", http.StatusBadRequest)
+		return
+	}
+
+	languages := request.Header.Get("Accept-Language")
+	statement, turn, err := bot.Respond(body, languages)
+	if err != nil {
+		SendError(writer, fmt.Sprintf("Invalid request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	response := &Response{
+		Question:   statement.Text,
+		Session:    statement.Session,
+		Message:    turn.Fallback,
+		Prompt:     turn.Prompt,
+		Slots:      turn.Slots,
+		Results:    answerResults(turn.Answers),
+		DidYouMean: didYouMean(turn.Answers),
+	}
+	response.Intent, response.IntentProbability = bot.Classify(statement.Text)
+	SendJson(writer, response)
+}
+
+func answerResults(answers []logic.Answer) []*QA {
+	var results []*QA
+	for _, answer := range answers {
+		if answer.Record.CorpusId > 0 || answer.Rule != "" || answer.Adapter != "" {
+			results = append(results, &QA{
+				Question:   answer.Record.Question,
+				Answer:     answer.Content,
+				Score:      answer.Confidence,
+				Context:    answer.Record.Context,
+				Contextual: answer.Record.Contextual,
+				State:      answer.Record.State,
+				Data:       answer.Record.Data,
+				Class:      answer.Record.Class,
+				ID:         answer.Record.CorpusId,
+				Rule:       answer.Rule,
+				Adapter:    answer.Adapter,
+				Expansions: answer.Expansions,
+			})
+		}
+	}
+	return results
+}
+
+// didYouMean returns the correction the answers were found with, if any.
+func didYouMean(answers []logic.Answer) string {
+	if len(answers) == 0 {
+		return ""
+	}
+	return answers[0].Correction
+}
+
 func updateProjectCorpus(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	project := vars["project"]
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil || id <= 0 {
+		SendError(writer, fmt.Sprintf("Invalid corpus ID '%s'", vars["id"]), http.StatusBadRequest)
+		return
+	}
+
+	chatbot, ok := getProjectChatBot(writer, project)
+	if !ok {
+		return
+	}
+
+	corp := factory.GetCorpusById(id)
+	if corp == nil || corp.Project != project {
+		SendError(writer, "Corpus not found", http.StatusNotFound)
+		return
+	}
+
+	previous := *corp
+	if err := ParseJsonBody(request, corp); err != nil {
+		SendError(writer, fmt.Sprintf("Unable to parse request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	corp.Id = id
+	corp.Project = project
+	if corp.Question == "" {
+		SendError(writer, "Question must not be empty", http.StatusBadRequest)
+		return
+	}
+	if err := corp.Validate(); err != nil {
+		SendError(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
 
+	if err := chatbot.UpdateCorpusInDB(&previous, corp); err != nil {
+		SendError(writer, fmt.Sprintf("Could not update corpus: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, corp)
 }
 
 func deleteProjectCorpus(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	project := vars["project"]
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil || id <= 0 {
+		SendError(writer, fmt.Sprintf("Invalid corpus ID '%s'", vars["id"]), http.StatusBadRequest)
+		return
+	}
+
+	chatbot, ok := getProjectChatBot(writer, project)
+	if !ok {
+		return
+	}
+
+	corp := factory.GetCorpusById(id)
+	if corp == nil || corp.Project != project {
+		SendError(writer, "Corpus not found", http.StatusNotFound)
+		return
+	}
+
+	if err := chatbot.RemoveCorpusFromDB(corp); err != nil {
+		SendError(writer, fmt.Sprintf("Could not delete corpus: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, map[string]interface{}{"result": "ok"})
+}
+
+func listProjectRules(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	rules, err := chatbot.ListRules()
+	if err != nil {
+		SendError(writer, fmt.Sprintf("Could not list rules: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, rules)
+}
+
+func addProjectRule(writer http.ResponseWriter, request *http.Request) {
+	var rule bot.Rule
+	if err := ParseJsonBody(request, &rule); err != nil {
+		SendError(writer, fmt.Sprintf("Unable to parse request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	rule.Id = 0
+	if err := chatbot.AddRuleToDB(&rule); err != nil {
+		SendError(writer, fmt.Sprintf("Could not add rule: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	SendJson(writer, rule)
+}
+
+func deleteProjectRule(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil || id <= 0 {
+		SendError(writer, fmt.Sprintf("Invalid rule ID '%s'", vars["id"]), http.StatusBadRequest)
+		return
+	}
+
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
 
+	if err := chatbot.RemoveRuleFromDB(id); err == bot.ErrRuleNotFound {
+		SendError(writer, "Rule not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		SendError(writer, fmt.Sprintf("Could not delete rule: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, map[string]interface{}{"result": "ok"})
+}
+
+func listProjectSynonyms(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	synonyms, err := chatbot.ListSynonyms()
+	if err != nil {
+		SendError(writer, fmt.Sprintf("Could not list synonyms: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, synonyms)
+}
+
+func addProjectSynonym(writer http.ResponseWriter, request *http.Request) {
+	var synonym bot.Synonym
+	if err := ParseJsonBody(request, &synonym); err != nil {
+		SendError(writer, fmt.Sprintf("Unable to parse request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	synonym.Id = 0
+	if err := chatbot.AddSynonymToDB(&synonym); err != nil {
+		SendError(writer, fmt.Sprintf("Could not add synonyms: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	SendJson(writer, synonym)
+}
+
+func updateProjectSynonym(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil || id <= 0 {
+		SendError(writer, fmt.Sprintf("Invalid synonym ID '%s'", vars["id"]), http.StatusBadRequest)
+		return
+	}
+
+	var synonym bot.Synonym
+	if err := ParseJsonBody(request, &synonym); err != nil {
+		SendError(writer, fmt.Sprintf("Unable to parse request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	synonym.Id = id
+	if err := chatbot.UpdateSynonymInDB(&synonym); err == bot.ErrSynonymNotFound {
+		SendError(writer, "Synonym set not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		SendError(writer, fmt.Sprintf("Could not update synonyms: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	SendJson(writer, synonym)
+}
+
+func deleteProjectSynonym(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil || id <= 0 {
+		SendError(writer, fmt.Sprintf("Invalid synonym ID '%s'", vars["id"]), http.StatusBadRequest)
+		return
+	}
+
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	if err := chatbot.RemoveSynonymFromDB(id); err == bot.ErrSynonymNotFound {
+		SendError(writer, "Synonym set not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		SendError(writer, fmt.Sprintf("Could not delete synonyms: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, map[string]interface{}{"result": "ok"})
+}
+
+func getProjectStopWords(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	stopWords, ok := chatbot.StopWords()
+	if !ok {
+		SendError(writer, fmt.Sprintf("Project %s does not detect stop words", vars["project"]), http.StatusNotFound)
+		return
+	}
+	SendJson(writer, stopWords)
+}
+
+// updateProjectStopWords replaces the terms always or never stop words, and
+// sends the stop words with them.
+func updateProjectStopWords(writer http.ResponseWriter, request *http.Request) {
+	var overrides bot.StopWordOverrides
+	if err := ParseJsonBody(request, &overrides); err != nil {
+		SendError(writer, fmt.Sprintf("Unable to parse request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	if err := chatbot.SaveStopWordOverrides(overrides); err != nil {
+		SendError(writer, fmt.Sprintf("Could not save stop words: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	stopWords, _ := chatbot.StopWords()
+	SendJson(writer, stopWords)
+}
+
+func listProjectResources(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	resources, err := chatbot.ListResources()
+	if err != nil {
+		SendError(writer, fmt.Sprintf("Could not list resources: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, resources)
+}
+
+func reloadProjectResources(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	if err := chatbot.ReloadResources(); err != nil {
+		SendError(writer, fmt.Sprintf("Could not reload resources: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, map[string]interface{}{"result": "ok"})
+}
+
+// getProjectResource sends the resource as it is loaded, in its text format.
+func getProjectResource(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	if err := storage.ValidateResource(vars["name"], nil); err != nil {
+		SendError(writer, err.Error(), http.StatusNotFound)
+		return
+	}
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	data, err := chatbot.Resource(vars["name"])
+	if err != nil {
+		SendError(writer, fmt.Sprintf("Could not load resource: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writer.Write(data)
+}
+
+// updateProjectResource replaces the resource with the request body, in its
+// text format.
+func updateProjectResource(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	if err := storage.ValidateResource(vars["name"], nil); err != nil {
+		SendError(writer, err.Error(), http.StatusNotFound)
+		return
+	}
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		SendError(writer, fmt.Sprintf("Unable to read request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	if err := chatbot.SaveResourceToDB(vars["name"], data); err != nil {
+		SendError(writer, fmt.Sprintf("Could not save resource: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	SendJson(writer, map[string]interface{}{"result": "ok"})
+}
+
+func deleteProjectResource(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	if err := chatbot.RemoveResourceFromDB(vars["name"]); err == bot.ErrResourceNotFound {
+		SendError(writer, "Resource not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		SendError(writer, fmt.Sprintf("Could not delete resource: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, map[string]interface{}{"result": "ok"})
 }
 
 func addProjectCorpus(writer http.ResponseWriter, request *http.Request) {
@@ -217,7 +721,7 @@ func addProjectCorpus(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	if bot, ok := factory.GetChatBot(project); !ok {
-		SendError(writer, fmt.Sprintf("Could not initialize project %s", project)	, http.StatusInternalServerError)
+		SendError(writer, fmt.Sprintf("Could not initialize project %s", project), http.StatusInternalServerError)
 		return
 	} else {
 		if err := bot.AddCorpusToDB(&corpus); err != nil {
@@ -246,7 +750,19 @@ func listProjectCorpus(writer http.ResponseWriter, request *http.Request) {
 }
 
 func deleteProject(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	project := vars["project"]
 
+	if p := factory.GetProjectByName(project); p == nil {
+		SendError(writer, fmt.Sprintf("Project %s not found", project), http.StatusNotFound)
+		return
+	}
+
+	if err := factory.DeleteProject(project); err != nil {
+		SendError(writer, fmt.Sprintf("Could not delete project %s: %s", project, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, map[string]interface{}{"result": "ok"})
 }
 
 func addProject(writer http.ResponseWriter, request *http.Request) {
@@ -263,7 +779,94 @@ func addProject(writer http.ResponseWriter, request *http.Request) {
 }
 
 func getProject(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	project := vars["project"]
 
+	if p := factory.GetProjectByName(project); p == nil {
+		SendError(writer, fmt.Sprintf("Project %s not found", project), http.StatusNotFound)
+	} else {
+		SendJson(writer, p)
+	}
+}
+
+// getProjectDialogue sends the dialogue states of the project with the issues
+// found in them.
+func getProjectDialogue(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	graph, err := chatbot.DialogueGraph()
+	if err != nil {
+		SendError(writer, fmt.Sprintf("Could not load corpora: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	SendJson(writer, map[string]interface{}{
+		"states": graph.States,
+		"issues": graph.Validate(),
+	})
+}
+
+// getProjectIdf sends the idf of the terms of the questions, from the most
+// common, the "term" parameter keeps a single term and "limit" the first ones.
+func getProjectIdf(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	report, ok := chatbot.IdfReport()
+	if !ok {
+		SendError(writer, fmt.Sprintf("Project %s does not compute the idf of its questions", vars["project"]), http.StatusNotFound)
+		return
+	}
+	if term := strings.ToLower(request.URL.Query().Get("term")); term != "" {
+		terms := []storage.IdfTerm{}
+		for _, each := range report.Terms {
+			if each.Term == term {
+				terms = append(terms, each)
+			}
+		}
+		report.Terms = terms
+	}
+	if limit, err := strconv.Atoi(request.URL.Query().Get("limit")); err == nil && limit >= 0 && limit < len(report.Terms) {
+		report.Terms = report.Terms[:limit]
+	}
+	SendJson(writer, report)
+}
+
+// explainProjectAnswers sends the score of each signal of the candidates of
+// the "q" parameter, to tune the weights of the project, "context" is the
+// context of the question.
+func explainProjectAnswers(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	query := request.URL.Query().Get("q")
+	if query == "" {
+		SendError(writer, "Question parameter q is missing", http.StatusBadRequest)
+		return
+	}
+
+	chatbot, ok := getProjectChatBot(writer, vars["project"])
+	if !ok {
+		return
+	}
+
+	var c []string
+	if context := request.URL.Query().Get("context"); context != "" {
+		c = []string{context}
+	}
+	explanations, ok := chatbot.Explain(query, c...)
+	if !ok {
+		SendError(writer, fmt.Sprintf("Project %s does not explain its answers", vars["project"]), http.StatusNotFound)
+		return
+	}
+	if explanations == nil {
+		explanations = []logic.Explanation{}
+	}
+	SendJson(writer, explanations)
 }
 
 func getProjectList(writer http.ResponseWriter, request *http.Request) {
@@ -271,6 +874,22 @@ func getProjectList(writer http.ResponseWriter, request *http.Request) {
 	SendJson(writer, projects)
 }
 
+// getProjectChatBot sends a 404 when the project does not exist, or a 500 when
+// its chat bot was never initialized.
+func getProjectChatBot(writer http.ResponseWriter, project string) (*bot.ChatBot, bool) {
+	if p := factory.GetProjectByName(project); p == nil {
+		SendError(writer, fmt.Sprintf("Project %s not found", project), http.StatusNotFound)
+		return nil, false
+	}
+
+	chatbot, ok := factory.GetChatBot(project)
+	if !ok {
+		SendError(writer, fmt.Sprintf("Could not initialize project %s", project), http.StatusInternalServerError)
+		return nil, false
+	}
+	return chatbot, true
+}
+
 func SendJson(w http.ResponseWriter, res interface{}, statusCode ...int) {
 	r, _ := json.MarshalIndent(res, "", "\t")
 	if len(statusCode) == 1 {
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeffdoubleyou/chatbot/bot"
+	"github.com/jeffdoubleyou/chatbot/bot/adapters/logic"
+)
+
+// TestMain serves a demo project from a scratch DB, with its resources in
+// files so that no default resource is needed.
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "chatbot")
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	code := 1
+	if err := initTestFactory(dir); err != nil {
+		fmt.Println(err.Error())
+	} else {
+		code = m.Run()
+	}
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func initTestFactory(dir string) error {
+	conf := bot.Config{
+		Tokenizer:     "word",
+		DictFile:      filepath.Join(dir, "dict.txt"),
+		IdfFile:       filepath.Join(dir, "idf.txt"),
+		StopWordsFile: filepath.Join(dir, "stop_words.txt"),
+	}
+	for file, data := range map[string]string{conf.DictFile: "printer 10\n", conf.IdfFile: "printer 5\n", conf.StopWordsFile: "the\n"} {
+		if err := ioutil.WriteFile(file, []byte(data), 0o600); err != nil {
+			return err
+		}
+	}
+	config, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	conf.Logic, conf.Intent = bot.LOGIC_HYBRID, true
+	hybrid, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+
+	factory = bot.NewChatBotFactory(bot.Config{Driver: "sqlite3", DataSource: filepath.Join(dir, "chatbot.db")})
+	factory.Init()
+	for _, project := range []string{"other", "demo", "obsolete"} {
+		if _, err := factory.AddProject(project, string(config)); err != nil {
+			return err
+		}
+	}
+	if _, err := factory.AddProject("hybrid", string(hybrid)); err != nil {
+		return err
+	}
+	// the chat bots of the new projects are created when the factory is
+	// initialized again
+	factory.Init()
+
+	// the first corpus belongs to another project than the tested one
+	other, ok := factory.GetChatBot("other")
+	if !ok {
+		return fmt.Errorf("project other was not initialized")
+	}
+	return other.AddCorpusToDB(&bot.Corpus{Project: "other", Question: "where is the fax?", Answer: "nowhere", Class: "fax"})
+}
+
+func serve(method, url, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	newRouter().ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+	return recorder
+}
+
+func addTestCorpus(t *testing.T, question, answer string) (*bot.ChatBot, *bot.Corpus) {
+	chatbot, ok := factory.GetChatBot("demo")
+	if !ok {
+		t.Fatal("expected the demo project to be initialized")
+	}
+	corpus := &bot.Corpus{Project: "demo", Question: question, Answer: answer, Qtype: int(bot.CORPUS_CORPUS)}
+	if err := chatbot.AddCorpusToDB(corpus); err != nil {
+		t.Fatal(err)
+	}
+	chatbot.AddCorpusToStorage(corpus)
+	chatbot.StorageAdapter.BuildIndex()
+	return chatbot, corpus
+}
+
+func TestUpdateProjectCorpus(t *testing.T) {
+	chatbot, corpus := addTestCorpus(t, "where is the printer?", "second floor")
+	url := fmt.Sprintf("/corpus/demo/%d", corpus.Id)
+
+	for _, test := range []struct {
+		url, body string
+		status    int
+	}{
+		{"/corpus/demo/printer", `{"question": "where is the printer?"}`, http.StatusBadRequest},
+		{url, `{"question":`, http.StatusBadRequest},
+		{url, `{"question": "", "answer": "third floor"}`, http.StatusBadRequest},
+		{"/corpus/demo/999999", `{"question": "where is the printer?"}`, http.StatusNotFound},
+		{fmt.Sprintf("/corpus/unknown/%d", corpus.Id), `{"question": "where is the printer?"}`, http.StatusNotFound},
+	} {
+		if recorder := serve("PUT", test.url, test.body); recorder.Code != test.status {
+			t.Errorf("PUT %s %s: expected %d, got %d %s", test.url, test.body, test.status, recorder.Code, recorder.Body)
+		}
+	}
+
+	body := `{"question": "where is the new printer?", "answer": "third floor", "qtype": 1}`
+	if recorder := serve("PUT", url, body); recorder.Code != http.StatusOK {
+		t.Fatalf("expected the corpus to be updated, got %d %s", recorder.Code, recorder.Body)
+	}
+	if records, ok := chatbot.StorageAdapter.Find("where is the new printer?"); !ok || len(records) != 1 || records[0].Answer != "third floor" {
+		t.Errorf("expected the storage to answer the updated question, got %v", records)
+	}
+	if records, ok := chatbot.StorageAdapter.Find("where is the printer?"); ok {
+		t.Errorf("expected the previous question to be removed from the storage, got %v", records)
+	}
+	if keys := chatbot.StorageAdapter.Search("new printer"); len(keys) != 1 || keys[0] != "where is the new printer?" {
+		t.Errorf("expected the updated question to be searchable without a rebuild, got %v", keys)
+	}
+}
+
+func TestDeleteProjectCorpus(t *testing.T) {
+	chatbot, corpus := addTestCorpus(t, "how to reset my password?", "use the portal")
+	url := fmt.Sprintf("/corpus/demo/%d", corpus.Id)
+
+	for _, test := range []struct {
+		url    string
+		status int
+	}{
+		{"/corpus/demo/0", http.StatusBadRequest},
+		{"/corpus/demo/999999", http.StatusNotFound},
+		{fmt.Sprintf("/corpus/unknown/%d", corpus.Id), http.StatusNotFound},
+	} {
+		if recorder := serve("DELETE", test.url, ""); recorder.Code != test.status {
+			t.Errorf("DELETE %s: expected %d, got %d %s", test.url, test.status, recorder.Code, recorder.Body)
+		}
+	}
+
+	if recorder := serve("DELETE", url, ""); recorder.Code != http.StatusOK {
+		t.Fatalf("expected the corpus to be deleted, got %d %s", recorder.Code, recorder.Body)
+	}
+	if records, ok := chatbot.StorageAdapter.Find("how to reset my password?"); ok {
+		t.Errorf("expected the question to be removed from the storage, got %v", records)
+	}
+	if keys := chatbot.StorageAdapter.Search("password"); len(keys) != 0 {
+		t.Errorf("expected the question not to be searchable without a rebuild, got %v", keys)
+	}
+	if recorder := serve("DELETE", url, ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected the deleted corpus not to be found, got %d", recorder.Code)
+	}
+}
+
+func TestAddFeedback(t *testing.T) {
+	_, corpus := addTestCorpus(t, "where is the scanner?", "first floor")
+
+	for _, test := range []struct {
+		url, body string
+		status    int
+	}{
+		{"/respond/feedback/demo", `{"cid":`, http.StatusBadRequest},
+		{"/respond/feedback/demo", `{"is_ok": true}`, http.StatusBadRequest},
+		{"/respond/feedback/demo", `{"cid": 999999, "is_ok": true}`, http.StatusNotFound},
+		{"/respond/feedback/unknown", `{"question": "where is the scanner?"}`, http.StatusNotFound},
+	} {
+		if recorder := serve("POST", test.url, test.body); recorder.Code != test.status {
+			t.Errorf("POST %s %s: expected %d, got %d %s", test.url, test.body, test.status, recorder.Code, recorder.Body)
+		}
+	}
+
+	recorder := serve("POST", "/respond/feedback/demo", `{"question": "is there a fax?"}`)
+	var feedback bot.Feedback
+	if err := json.Unmarshal(recorder.Body.Bytes(), &feedback); recorder.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected the feedback on a question to be added, got %d %s", recorder.Code, recorder.Body)
+	}
+	if feedback.Project != "demo" || feedback.Class != "" {
+		t.Errorf("expected the feedback in the demo project without a class, got %+v", feedback)
+	}
+
+	body := fmt.Sprintf(`{"cid": %d, "question": "where is the scanner?", "is_ok": true}`, corpus.Id)
+	if recorder := serve("POST", "/respond/feedback/demo", body); recorder.Code != http.StatusOK {
+		t.Fatalf("expected the feedback to be added, got %d %s", recorder.Code, recorder.Body)
+	}
+	if updated := factory.GetCorpusById(corpus.Id); updated == nil || updated.AcceptCount != 1 {
+		t.Errorf("expected the corpus to be accepted once, got %v", updated)
+	}
+}
+
+func TestProject(t *testing.T) {
+	if recorder := serve("GET", "/project/demo", ""); recorder.Code != http.StatusOK {
+		t.Errorf("expected the demo project, got %d %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("GET", "/project/unknown", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown project not to be found, got %d", recorder.Code)
+	}
+
+	if recorder := serve("DELETE", "/project/obsolete", ""); recorder.Code != http.StatusOK {
+		t.Fatalf("expected the project to be deleted, got %d %s", recorder.Code, recorder.Body)
+	}
+	if _, ok := factory.GetChatBot("obsolete"); ok {
+		t.Error("expected the chat bot of the deleted project to be dropped")
+	}
+	for _, method := range []string{"GET", "DELETE"} {
+		if recorder := serve(method, "/project/obsolete", ""); recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: expected the deleted project not to be found, got %d", method, recorder.Code)
+		}
+	}
+}
+
+func TestExplainProjectAnswers(t *testing.T) {
+	chatbot, ok := factory.GetChatBot("hybrid")
+	if !ok {
+		t.Fatal("expected the hybrid project to be initialized")
+	}
+	chatbot.AddCorpusToStorage(&bot.Corpus{Id: 1, Project: "hybrid", Question: "how to reset my password?", Answer: "use the portal"})
+	chatbot.StorageAdapter.BuildIndex()
+
+	for _, test := range []struct {
+		url    string
+		status int
+	}{
+		{"/project/hybrid/explain", http.StatusBadRequest},
+		{"/project/unknown/explain?q=password", http.StatusNotFound},
+		{"/project/demo/explain?q=password", http.StatusNotFound},
+	} {
+		if recorder := serve("GET", test.url, ""); recorder.Code != test.status {
+			t.Errorf("GET %s: expected %d, got %d %s", test.url, test.status, recorder.Code, recorder.Body)
+		}
+	}
+
+	recorder := serve("GET", "/project/hybrid/explain?q=reset+password", "")
+	var explanations []logic.Explanation
+	if err := json.Unmarshal(recorder.Body.Bytes(), &explanations); recorder.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected the explanations, got %d %s", recorder.Code, recorder.Body)
+	}
+	if len(explanations) != 1 || explanations[0].Question != "how to reset my password?" {
+		t.Fatalf("expected the password question, got %+v", explanations)
+	}
+	for _, signal := range []string{logic.SignalSimilarity, logic.SignalJaccard, logic.SignalBM25, logic.SignalFeedback} {
+		if _, ok := explanations[0].Signals[signal]; !ok {
+			t.Errorf("expected the %s signal, got %v", signal, explanations[0].Signals)
+		}
+	}
+}